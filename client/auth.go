@@ -0,0 +1,232 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/duke0x/ts-notifier/config"
+)
+
+var ErrUnknownAuthMode = errors.New("unknown jira auth mode")
+
+// tokenExpiryDelta is a time before the access token expiration
+// when the token is treated as expired and refreshed
+const tokenExpiryDelta = 30 * time.Second
+
+// authorizer sets request credentials
+type authorizer interface {
+	authorize(req *http.Request) error
+}
+
+// newJiraAuthorizer returns authorizer of the configured Jira auth mode.
+// OAuth2 access tokens are requested with the client.
+func newJiraAuthorizer(cfg config.Jira, client *http.Client) (authorizer, error) {
+	switch cfg.AuthMode {
+	case "", config.JiraAuthBasic:
+		return basicAuth{user: cfg.UserEmail, token: cfg.AuthToken}, nil
+	case config.JiraAuthBearer:
+		return bearerAuth(cfg.AuthToken), nil
+	case config.JiraAuthOAuth2:
+		return &oauth2Auth{client: client, config: cfg.OAuth2}, nil
+	default:
+		return nil, fmt.Errorf("%w: '%s'", ErrUnknownAuthMode, cfg.AuthMode)
+	}
+}
+
+// withAuth returns a copy of the http client which authorizes
+// requests to the service with the base URL scheme and host
+func withAuth(client *http.Client, auth authorizer, baseURL string) (*http.Client, error) {
+	base, err := url.Parse(baseURL)
+	if err != nil {
+		return nil, fmt.Errorf("parsing service url: %w", err)
+	}
+
+	c := *client
+	c.Transport = &authTransport{next: client.Transport, auth: auth, scheme: base.Scheme, host: base.Host}
+
+	return &c, nil
+}
+
+// authTransport sets credentials of requests to the service host only,
+// so they are not sent to other hosts the service redirects to.
+// Request rejected as unauthorized is sent once more with a new OAuth2 token.
+type authTransport struct {
+	next   http.RoundTripper
+	auth   authorizer
+	scheme string
+	host   string
+}
+
+func (t *authTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	next := t.next
+	if next == nil {
+		next = http.DefaultTransport
+	}
+
+	if req.URL.Scheme != t.scheme || req.URL.Host != t.host {
+		return next.RoundTrip(req)
+	}
+
+	resp, err := t.send(next, req)
+	a, ok := t.auth.(*oauth2Auth)
+	if err != nil || resp.StatusCode != http.StatusUnauthorized || !ok {
+		return resp, err
+	}
+
+	// cached token could be revoked before its expiration
+	a.reset()
+	retry, rerr := rewind(req)
+	if rerr != nil {
+		return resp, nil
+	}
+	_, _ = io.Copy(io.Discard, resp.Body)
+	_ = resp.Body.Close()
+
+	return t.send(next, retry)
+}
+
+// send sends the authorized request copy
+func (t *authTransport) send(next http.RoundTripper, req *http.Request) (*http.Response, error) {
+	r := req.Clone(req.Context())
+	if err := t.auth.authorize(r); err != nil {
+		return nil, err
+	}
+
+	return next.RoundTrip(r)
+}
+
+// basicAuth authorizes requests with user email and API token
+type basicAuth struct {
+	user  string
+	token string
+}
+
+func (a basicAuth) authorize(req *http.Request) error {
+	req.SetBasicAuth(a.user, a.token)
+	return nil
+}
+
+// bearerAuth authorizes requests with personal access token
+type bearerAuth string
+
+func (a bearerAuth) authorize(req *http.Request) error {
+	req.Header.Set("Authorization", "Bearer "+string(a))
+	return nil
+}
+
+// oauth2Auth authorizes requests with access token obtained
+// with OAuth2 client credentials grant. The token is cached until it expires.
+type oauth2Auth struct {
+	client *http.Client
+	config config.OAuth2
+
+	mu     sync.Mutex
+	token  string
+	expiry time.Time
+}
+
+type tokenResponse struct {
+	AccessToken string `json:"access_token"`
+	TokenType   string `json:"token_type"`
+	ExpiresIn   int64  `json:"expires_in"`
+}
+
+func (a *oauth2Auth) authorize(req *http.Request) error {
+	token, err := a.accessToken(req.Context())
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+
+	return nil
+}
+
+// accessToken returns cached access token or requests the new one
+func (a *oauth2Auth) accessToken(ctx context.Context) (string, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if a.token != "" && (a.expiry.IsZero() || time.Now().Before(a.expiry.Add(-tokenExpiryDelta))) {
+		return a.token, nil
+	}
+
+	tr, err := a.fetchToken(ctx)
+	if err != nil {
+		return "", err
+	}
+	a.token = tr.AccessToken
+	a.expiry = time.Time{}
+	if tr.ExpiresIn > 0 {
+		a.expiry = time.Now().Add(time.Duration(tr.ExpiresIn) * time.Second)
+	}
+
+	return a.token, nil
+}
+
+// reset drops the cached access token
+func (a *oauth2Auth) reset() {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	a.token = ""
+}
+
+func (a *oauth2Auth) fetchToken(ctx context.Context) (tokenResponse, error) {
+	form := url.Values{
+		"grant_type":    {"client_credentials"},
+		"client_id":     {a.config.ClientID},
+		"client_secret": {a.config.ClientSecret},
+	}
+	if len(a.config.Scopes) > 0 {
+		form.Set("scope", strings.Join(a.config.Scopes, " "))
+	}
+
+	req, err := http.NewRequestWithContext(
+		ctx,
+		http.MethodPost,
+		a.config.TokenURL,
+		strings.NewReader(form.Encode()),
+	)
+	if err != nil {
+		return tokenResponse{}, fmt.Errorf("creating 'get access token' request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := a.client.Do(req)
+	if err != nil {
+		return tokenResponse{}, fmt.Errorf("sending 'get access token' request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	rspData, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return tokenResponse{}, fmt.Errorf("reading 'get access token' response: %w", err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return tokenResponse{}, fmt.Errorf(
+			"%w: token endpoint returned %d status",
+			ErrUnauthorized,
+			resp.StatusCode,
+		)
+	}
+
+	var tr tokenResponse
+	if err := json.Unmarshal(rspData, &tr); err != nil {
+		return tokenResponse{}, fmt.Errorf("decoding 'get access token' response: %w", err)
+	}
+	if tr.AccessToken == "" {
+		return tokenResponse{}, fmt.Errorf("%w: token endpoint returned no access token", ErrUnauthorized)
+	}
+
+	return tr, nil
+}
@@ -0,0 +1,185 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/duke0x/ts-notifier/config"
+)
+
+func TestJiraAuthModes(t *testing.T) {
+	tests := []struct {
+		name string
+		cfg  config.Jira
+		want string
+	}{
+		{
+			name: "basic by default",
+			cfg:  config.Jira{UserEmail: "user@example.com", AuthToken: "token"},
+			want: "Basic dXNlckBleGFtcGxlLmNvbTp0b2tlbg==",
+		},
+		{
+			name: "bearer",
+			cfg:  config.Jira{AuthMode: config.JiraAuthBearer, AuthToken: "pat"},
+			want: "Bearer pat",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got string
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				got = r.Header.Get("Authorization")
+				_, _ = w.Write([]byte(`{"issues": []}`))
+			}))
+			defer srv.Close()
+
+			tt.cfg.URL = srv.URL
+			jc, err := NewJiraCli(srv.Client(), tt.cfg, nil)
+			require.NoError(t, err)
+
+			_, err = jc.UserWorkedIssuesByDate(context.Background(), "user1", time.Now(), "")
+			require.NoError(t, err)
+			require.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestJiraAuthUnknownMode(t *testing.T) {
+	_, err := NewJiraCli(http.DefaultClient, config.Jira{AuthMode: "ntlm"}, nil)
+	require.ErrorIs(t, err, ErrUnknownAuthMode)
+}
+
+func TestJiraAuthOAuth2(t *testing.T) {
+	var issued, rejected atomic.Int32
+	mux := http.NewServeMux()
+	mux.HandleFunc("/oauth/token", func(w http.ResponseWriter, r *http.Request) {
+		require.NoError(t, r.ParseForm())
+		require.Equal(t, "client_credentials", r.PostForm.Get("grant_type"))
+		require.Equal(t, "client", r.PostForm.Get("client_id"))
+		require.Equal(t, "secret", r.PostForm.Get("client_secret"))
+		require.Equal(t, "read:jira-work read:jira-user", r.PostForm.Get("scope"))
+
+		n := issued.Add(1)
+		_, _ = fmt.Fprintf(w, `{"access_token": "token%d", "token_type": "Bearer", "expires_in": 3600}`, n)
+	})
+	mux.HandleFunc("/rest/api/2/search", func(w http.ResponseWriter, r *http.Request) {
+		// the first token is revoked after two requests
+		if r.Header.Get("Authorization") == "Bearer token1" && rejected.Add(1) > 2 {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		_, _ = w.Write([]byte(`{"issues": []}`))
+	})
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	jc, err := NewJiraCli(srv.Client(), config.Jira{
+		URL:      srv.URL,
+		AuthMode: config.JiraAuthOAuth2,
+		OAuth2: config.OAuth2{
+			TokenURL:     srv.URL + "/oauth/token",
+			ClientID:     "client",
+			ClientSecret: "secret",
+			Scopes:       []string{"read:jira-work", "read:jira-user"},
+		},
+	}, nil)
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	for i := 0; i < 2; i++ {
+		_, err = jc.UserWorkedIssuesByDate(ctx, "user1", time.Now(), "")
+		require.NoError(t, err)
+	}
+	require.Equal(t, int32(1), issued.Load(), "token must be cached")
+
+	// rejected request is sent once more with a new token
+	_, err = jc.UserWorkedIssuesByDate(ctx, "user1", time.Now(), "")
+	require.NoError(t, err)
+	require.Equal(t, int32(2), issued.Load(), "rejected token must be refreshed")
+}
+
+func TestJiraAuthOAuth2Rejected(t *testing.T) {
+	var issued, searched atomic.Int32
+	mux := http.NewServeMux()
+	mux.HandleFunc("/oauth/token", func(w http.ResponseWriter, r *http.Request) {
+		issued.Add(1)
+		_, _ = w.Write([]byte(`{"access_token": "token", "expires_in": 3600}`))
+	})
+	mux.HandleFunc("/rest/api/2/search", func(w http.ResponseWriter, r *http.Request) {
+		searched.Add(1)
+		w.WriteHeader(http.StatusUnauthorized)
+	})
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	jc, err := NewJiraCli(srv.Client(), config.Jira{
+		URL:      srv.URL,
+		AuthMode: config.JiraAuthOAuth2,
+		OAuth2:   config.OAuth2{TokenURL: srv.URL + "/oauth/token"},
+	}, nil)
+	require.NoError(t, err)
+
+	_, err = jc.UserWorkedIssuesByDate(context.Background(), "user1", time.Now(), "")
+	require.ErrorIs(t, err, ErrUnauthorized)
+	require.Equal(t, int32(2), searched.Load(), "request must be retried once")
+	require.Equal(t, int32(2), issued.Load())
+}
+
+func TestJiraAuthRedirect(t *testing.T) {
+	var got string
+	other := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = r.Header.Get("Authorization")
+		_, _ = w.Write([]byte(`{"issues": []}`))
+	}))
+	defer other.Close()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, other.URL+r.URL.RequestURI(), http.StatusFound)
+	}))
+	defer srv.Close()
+
+	jc, err := NewJiraCli(srv.Client(), config.Jira{
+		URL:       srv.URL,
+		AuthMode:  config.JiraAuthBearer,
+		AuthToken: "pat",
+	}, nil)
+	require.NoError(t, err)
+
+	_, err = jc.UserWorkedIssuesByDate(context.Background(), "user1", time.Now(), "")
+	require.NoError(t, err)
+	require.Empty(t, got, "credentials must not be sent to other hosts")
+}
+
+func TestOAuth2TokenExpiry(t *testing.T) {
+	var issued atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		issued.Add(1)
+		// token expiring within tokenExpiryDelta is refreshed on every request
+		_, _ = w.Write([]byte(`{"access_token": "token", "expires_in": 10}`))
+	}))
+	defer srv.Close()
+
+	a := &oauth2Auth{client: srv.Client(), config: config.OAuth2{TokenURL: srv.URL}}
+	for i := 0; i < 2; i++ {
+		token, err := a.accessToken(context.Background())
+		require.NoError(t, err)
+		require.Equal(t, "token", token)
+	}
+	require.Equal(t, int32(2), issued.Load())
+
+	failing := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+	}))
+	defer failing.Close()
+
+	a = &oauth2Auth{client: failing.Client(), config: config.OAuth2{TokenURL: failing.URL}}
+	_, err := a.accessToken(context.Background())
+	require.ErrorIs(t, err, ErrUnauthorized)
+}
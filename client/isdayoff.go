@@ -5,11 +5,13 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"log/slog"
 	"net/http"
 	"strconv"
 	"strings"
 	"time"
 
+	"github.com/duke0x/ts-notifier/config"
 	"github.com/duke0x/ts-notifier/model"
 )
 
@@ -28,20 +30,27 @@ const (
 	errUnavailable = 199
 )
 
-const IsDayOffURL = "https://isdayoff.ru"
+const (
+	IsDayOffURL            = "https://isdayoff.ru"
+	defaultIsDayOffTimeout = 10 * time.Second
+)
 
 type IsDayOff struct {
 	client *http.Client
 	url    string
+	logger *slog.Logger
 }
 
-func NewIsDayOff(client *http.Client, srvURL string) IsDayOff {
+func NewIsDayOff(client *http.Client, cfg config.IsDayOff, logger *slog.Logger) IsDayOff {
 	url := IsDayOffURL
-	if srvURL != "" {
-		url = srvURL
+	if cfg.URL != "" {
+		url = cfg.URL
 	}
+	logger = orDefault(logger)
+	client = withTimeout(withLogger(client, logger), cfg.Timeout, defaultIsDayOffTimeout)
+	client = withRetry(client, cfg.Retry, logger)
 
-	return IsDayOff{client: client, url: url}
+	return IsDayOff{client: client, url: url, logger: logger}
 }
 
 // FetchDayType returns the type of day: working, non-working or shortened day.
@@ -97,5 +106,7 @@ func (i IsDayOff) FetchDayType(ctx context.Context, dt time.Time) (model.DayType
 		return model.DayError, ErrServiceUnavailable
 	}
 
+	i.logger.DebugContext(ctx, "day type fetched", "day", day, "type", model.DayType(rc))
+
 	return model.DayType(rc), nil
 }
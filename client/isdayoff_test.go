@@ -8,6 +8,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/duke0x/ts-notifier/config"
 	"github.com/duke0x/ts-notifier/model"
 	"github.com/stretchr/testify/require"
 )
@@ -25,7 +26,7 @@ func TestIsDayOff_FetchDayType(t *testing.T) {
 			_, _ = w.Write([]byte("0"))
 		}))
 
-		isDayOffClient := NewIsDayOff(srv.Client(), srv.URL)
+		isDayOffClient := NewIsDayOff(srv.Client(), config.IsDayOff{URL: srv.URL}, nil)
 		got, err := isDayOffClient.FetchDayType(context.Background(), time.Now())
 		require.NoError(t, err)
 		require.Equal(t, model.WorkDay, got)
@@ -149,7 +150,11 @@ func TestIsDayOff_FetchDayTypeRespStatusCode(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			isDayOffClient := NewIsDayOff(tt.args.srv.Client(), tt.args.srv.URL)
+			isDayOffClient := NewIsDayOff(
+				tt.args.srv.Client(),
+				config.IsDayOff{URL: tt.args.srv.URL, Retry: config.Retry{MaxAttempts: 1}},
+				nil,
+			)
 			dt, err := isDayOffClient.FetchDayType(context.Background(), time.Now())
 			require.Equal(t, model.DayError, dt)
 			require.EqualError(t, err, tt.wantErr.Error())
@@ -3,9 +3,12 @@ package client
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
+	"log/slog"
 	"net/http"
+	"sort"
 	"strconv"
 	"strings"
 	"time"
@@ -14,14 +17,112 @@ import (
 	"github.com/duke0x/ts-notifier/model"
 )
 
+// jiraTimeFormat is a format of work log times in Jira responses
+const jiraTimeFormat = "2006-01-02T15:04:05.999-0700"
+
+var (
+	ErrUnauthorized     = errors.New("jira authentication failed")
+	ErrForbidden        = errors.New("jira access forbidden")
+	ErrBadJQL           = errors.New("jira rejected search query")
+	ErrNotFound         = errors.New("jira resource not found")
+	ErrRateLimited      = errors.New("jira rate limit exceeded")
+	ErrUnexpectedStatus = errors.New("jira returned unexpected status")
+)
+
+// JiraError is an unsuccessful Jira response error.
+// It wraps one of the Jira sentinel errors depending on status code.
+type JiraError struct {
+	StatusCode int
+	// Messages are error messages from the response body
+	Messages []string
+	Err      error
+}
+
+func (e *JiraError) Error() string {
+	msg := fmt.Sprintf("%s (%d)", e.Err.Error(), e.StatusCode)
+	if len(e.Messages) > 0 {
+		msg += ": " + strings.Join(e.Messages, "; ")
+	}
+
+	return msg
+}
+
+func (e *JiraError) Unwrap() error {
+	return e.Err
+}
+
+// errorResponse stores Jira error response body
+type errorResponse struct {
+	ErrorMessages []string          `json:"errorMessages"`
+	Errors        map[string]string `json:"errors"`
+}
+
+// checkResponse returns JiraError if the response status is not successful.
+// Bad request status is reported with badRequest error.
+func checkResponse(resp *http.Response, body []byte, badRequest error) error {
+	if resp.StatusCode >= http.StatusOK && resp.StatusCode < http.StatusMultipleChoices {
+		return nil
+	}
+
+	je := &JiraError{StatusCode: resp.StatusCode}
+	switch resp.StatusCode {
+	case http.StatusBadRequest:
+		je.Err = badRequest
+	case http.StatusUnauthorized:
+		je.Err = ErrUnauthorized
+	case http.StatusForbidden:
+		je.Err = ErrForbidden
+	case http.StatusNotFound:
+		je.Err = ErrNotFound
+	case http.StatusTooManyRequests:
+		je.Err = ErrRateLimited
+	default:
+		je.Err = ErrUnexpectedStatus
+	}
+
+	var er errorResponse
+	if err := json.Unmarshal(body, &er); err == nil {
+		je.Messages = append(je.Messages, er.ErrorMessages...)
+		fields := make([]string, 0, len(er.Errors))
+		for field := range er.Errors {
+			fields = append(fields, field)
+		}
+		sort.Strings(fields)
+		for _, field := range fields {
+			je.Messages = append(je.Messages, field+": "+er.Errors[field])
+		}
+	}
+
+	return je
+}
+
+const (
+	defaultJiraTimeout   = 30 * time.Second
+	defaultJiraEpicField = "customfield_10014"
+)
+
 // Jira fetched work-logs data from jira worklogs service
 type Jira struct {
 	client *http.Client
 	config config.Jira
+	logger *slog.Logger
 }
 
-func NewJiraCli(client *http.Client, params config.Jira) *Jira {
-	return &Jira{client: client, config: params}
+// NewJiraCli returns Jira client authorizing requests with the configured auth mode
+func NewJiraCli(client *http.Client, params config.Jira, logger *slog.Logger) (*Jira, error) {
+	logger = orDefault(logger)
+
+	client = withTimeout(withLogger(client, logger), params.Timeout, defaultJiraTimeout)
+	client = withRetry(client, params.Retry, logger)
+	auth, err := newJiraAuthorizer(params, client)
+	if err != nil {
+		return nil, err
+	}
+	if client, err = withAuth(client, auth, params.URL); err != nil {
+		return nil, err
+	}
+
+	return &Jira{client: client, config: params, logger: logger}, nil
 }
 
 func (wls *Jira) WorkLogsPerIssues(
@@ -35,15 +136,14 @@ func (wls *Jira) WorkLogsPerIssues(
 	for _, issue := range issues {
 		fullURL := fmt.Sprintf("%s/rest/api/2/issue/%s/worklog", wls.config.URL, issue.Key)
 		req, err := http.NewRequestWithContext(ctx, http.MethodGet, fullURL, nil)
+		if err != nil {
+			return nil, fmt.Errorf("creating request: %w", err)
+		}
 		qp := req.URL.Query()
 		qp.Add("startedAfter", strconv.FormatInt(startedAfter.UnixMilli(), 10))
 		qp.Add("startedBefore", strconv.FormatInt(startedBefore.UnixMilli(), 10))
 		req.URL.RawQuery = qp.Encode()
-		if err != nil {
-			return nil, fmt.Errorf("creating request: %w", err)
-		}
 
-		req.SetBasicAuth(wls.config.UserEmail, wls.config.AuthToken)
 		req.Header.Set("Accept", "application/json")
 
 		resp, err := wls.client.Do(req)
@@ -52,10 +152,14 @@ func (wls *Jira) WorkLogsPerIssues(
 		}
 
 		rspData, err := io.ReadAll(resp.Body)
+		_ = resp.Body.Close()
 		if err != nil {
 			return nil, fmt.Errorf("reqding jira_worklogs 'get worklogs' response: %w", err)
 		}
-		_ = resp.Body.Close()
+
+		if err := checkResponse(resp, rspData, ErrUnexpectedStatus); err != nil {
+			return nil, fmt.Errorf("fetching issue '%s' worklogs: %w", issue.Key, err)
+		}
 
 		var wlResp workLogResponse
 		err = json.Unmarshal(rspData, &wlResp)
@@ -68,17 +172,25 @@ func (wls *Jira) WorkLogsPerIssues(
 				continue
 			}
 
-			st, err := time.Parse("2006-01-02T15:04:05.999-0700", worklog.Started)
+			st, err := time.Parse(jiraTimeFormat, worklog.Started)
 			if err != nil {
+				wls.logger.WarnContext(ctx, "skipping work log with bad start time",
+					"issue", issue.Key, "started", worklog.Started, "error", err)
 				continue
 			}
 
+			// creation and update times are optional for time spends calculation
+			created, _ := time.Parse(jiraTimeFormat, worklog.Created)
+			updated, _ := time.Parse(jiraTimeFormat, worklog.Updated)
+
 			wl = append(wl, model.WorkLog{
 				Key:              issue.Key,
 				User:             user,
 				TimeSpentSeconds: worklog.TimeSpentSeconds,
 				Started:          st,
 				Comment:          worklog.Comment,
+				Created:          created,
+				Updated:          updated,
 			})
 		}
 	}
@@ -86,10 +198,13 @@ func (wls *Jira) WorkLogsPerIssues(
 	return wl, nil
 }
 
+// UserWorkedIssuesByDate returns issues the user logged work to on the date.
+// Not empty scope is an additional JQL condition limiting the issues.
 func (wls *Jira) UserWorkedIssuesByDate(
 	ctx context.Context,
 	user model.User,
 	date time.Time,
+	scope string,
 ) ([]model.Issue, error) {
 	url := strings.Join([]string{
 		wls.config.URL,
@@ -103,7 +218,6 @@ func (wls *Jira) UserWorkedIssuesByDate(
 	}
 
 	// Set headers
-	req.SetBasicAuth(wls.config.UserEmail, wls.config.AuthToken)
 	req.Header.Set("Accept", "application/json")
 
 	jql := fmt.Sprintf(
@@ -111,11 +225,14 @@ func (wls *Jira) UserWorkedIssuesByDate(
 		date.Format("2006-01-02"),
 		user,
 	)
+	if scope != "" {
+		jql += " AND " + scope
+	}
 
 	// Set query params
 	qp := req.URL.Query()
 	qp.Add("jql", jql)
-	qp.Add("fields", "summary")
+	qp.Add("fields", strings.Join([]string{"summary", "project", "parent", wls.epicField()}, ","))
 	req.URL.RawQuery = qp.Encode()
 
 	// Send the request
@@ -131,6 +248,10 @@ func (wls *Jira) UserWorkedIssuesByDate(
 		return nil, fmt.Errorf("reqding jira_worklogs 'get worklogs' response: %w", err)
 	}
 
+	if err := checkResponse(resp, rspData, ErrBadJQL); err != nil {
+		return nil, fmt.Errorf("searching worked issues: %w", err)
+	}
+
 	var wlResp issuesResponse
 	err = json.Unmarshal(rspData, &wlResp)
 	if err != nil {
@@ -139,32 +260,85 @@ func (wls *Jira) UserWorkedIssuesByDate(
 
 	issues := make([]model.Issue, 0, len(wlResp.Issues))
 	for _, issue := range wlResp.Issues {
-		issues = append(issues, model.Issue{
-			ID:  issue.ID,
-			Key: issue.Key,
-		})
+		issues = append(issues, wls.issue(ctx, issue))
 	}
 
 	return issues, nil
 }
 
+func (wls *Jira) epicField() string {
+	if wls.config.EpicField != "" {
+		return wls.config.EpicField
+	}
+
+	return defaultJiraEpicField
+}
+
+// issue converts found issue to model.Issue. Issue epic is taken from
+// the epic link field or from the parent issue of 'Epic' type.
+// Broken fields are logged and skipped, they are optional for reports.
+func (wls *Jira) issue(ctx context.Context, si searchIssue) model.Issue {
+	issue := model.Issue{ID: si.ID, Key: si.Key}
+	if len(si.Fields) == 0 {
+		return issue
+	}
+
+	var fields issueFields
+	if err := json.Unmarshal(si.Fields, &fields); err != nil {
+		wls.logger.WarnContext(ctx, "skipping bad issue fields", "issue", si.Key, "error", err)
+		return issue
+	}
+	issue.Summary = fields.Summary
+	issue.Project = fields.Project.Key
+	if fields.Parent != nil && fields.Parent.Fields.IssueType.Name == "Epic" {
+		issue.Epic = fields.Parent.Key
+	}
+
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal(si.Fields, &raw); err == nil {
+		var epic string
+		if v, ok := raw[wls.epicField()]; ok && json.Unmarshal(v, &epic) == nil && epic != "" {
+			issue.Epic = epic
+		}
+	}
+
+	return issue
+}
+
 // issuesResponse stores issues work logs data as described in
 // https://developer.atlassian.om/cloud/jira/platform/rest/v3/api-group-issue-search/#api-rest-api-3-search-get
 type issuesResponse struct {
-	Expand     string `json:"expand"`
-	StartAt    int    `json:"startAt"`
-	MaxResults int    `json:"maxResults"`
-	Total      int    `json:"total"`
-	Issues     []struct {
-		Expand string `json:"expand"`
-		ID     string `json:"id"`
-		Self   string `json:"self"`
+	Expand     string        `json:"expand"`
+	StartAt    int           `json:"startAt"`
+	MaxResults int           `json:"maxResults"`
+	Total      int           `json:"total"`
+	Issues     []searchIssue `json:"issues"`
+}
+
+// searchIssue stores found issue, its fields are decoded separately
+// because the epic link field name is configured
+type searchIssue struct {
+	Expand string          `json:"expand"`
+	ID     string          `json:"id"`
+	Self   string          `json:"self"`
+	Key    string          `json:"key"`
+	Fields json.RawMessage `json:"fields,omitempty"`
+}
+
+type issueFields struct {
+	Summary string          `json:"summary"`
+	Worklog workLogResponse `json:"worklog"`
+	Project struct {
+		Key string `json:"key"`
+	} `json:"project"`
+	Parent *struct {
 		Key    string `json:"key"`
 		Fields struct {
-			Summary string          `json:"summary"`
-			Worklog workLogResponse `json:"worklog"`
+			IssueType struct {
+				Name string `json:"name"`
+			} `json:"issuetype"`
 		} `json:"fields"`
-	} `json:"issues"`
+	} `json:"parent"`
 }
 
 type workLogResponse struct {
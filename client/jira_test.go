@@ -3,6 +3,7 @@ package client
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"github.com/duke0x/ts-notifier/config"
 	"github.com/duke0x/ts-notifier/model"
@@ -30,7 +31,7 @@ func TestUserWorkedIssuesByDate(t *testing.T) {
 				require.Equal(t, "/rest/api/2/search", r.URL.Path)
 				require.Equal(t, http.MethodGet, r.Method)
 				rp := r.URL.Query()
-				require.Equal(t, "summary", rp.Get("fields"))
+				require.Equal(t, "summary,project,parent,customfield_10014", rp.Get("fields"))
 				jql := rp.Get("jql")
 				require.NotEmpty(t, jql)
 				_, _ = url.ParseQuery(jql)
@@ -50,11 +51,12 @@ func TestUserWorkedIssuesByDate(t *testing.T) {
 			srv := httptest.NewServer(tt.handler)
 			defer srv.Close()
 
-			jc := NewJiraCli(srv.Client(), config.Jira{
+			jc, err := NewJiraCli(srv.Client(), config.Jira{
 				URL: srv.URL,
-			})
+			}, nil)
+			require.NoError(t, err)
 
-			_, err := jc.UserWorkedIssuesByDate(context.Background(), user, date)
+			_, err = jc.UserWorkedIssuesByDate(context.Background(), user, date, "")
 			if tt.wantErr == nil {
 				require.NoError(t, err)
 			} else {
@@ -102,11 +104,12 @@ func TestWorkLogsPerIssues(t *testing.T) {
 			srv := httptest.NewServer(tt.handler)
 			defer srv.Close()
 
-			jc := NewJiraCli(srv.Client(), config.Jira{
+			jc, err := NewJiraCli(srv.Client(), config.Jira{
 				URL: srv.URL,
-			})
+			}, nil)
+			require.NoError(t, err)
 
-			_, err := jc.WorkLogsPerIssues(ctx, user, startedAfter, startedBefore, issues)
+			_, err = jc.WorkLogsPerIssues(ctx, user, startedAfter, startedBefore, issues)
 			if tt.wantErr == nil {
 				require.NoError(t, err)
 			} else {
@@ -115,3 +118,165 @@ func TestWorkLogsPerIssues(t *testing.T) {
 		})
 	}
 }
+
+func TestWorkLogsPerIssuesTimes(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write([]byte(`{"worklogs": [{
+			"author": {"accountId": "user1"},
+			"started": "2023-09-01T10:00:00.000+0000",
+			"created": "2023-09-04T11:30:00.000+0000",
+			"updated": "2023-09-05T09:00:00.000+0300",
+			"timeSpentSeconds": 3600
+		}]}`))
+	}))
+	defer srv.Close()
+
+	jc, err := NewJiraCli(srv.Client(), config.Jira{URL: srv.URL}, nil)
+	require.NoError(t, err)
+	day := time.Date(2023, 9, 1, 0, 0, 0, 0, time.UTC)
+	got, err := jc.WorkLogsPerIssues(
+		context.Background(),
+		"user1",
+		day,
+		day.Add(24*time.Hour),
+		[]model.Issue{{ID: "1", Key: "PRJ-1"}},
+	)
+	require.NoError(t, err)
+	require.Len(t, got, 1)
+	require.True(t, got[0].Started.Equal(day.Add(10*time.Hour)))
+	require.True(t, got[0].Created.Equal(time.Date(2023, 9, 4, 11, 30, 0, 0, time.UTC)))
+	require.True(t, got[0].Updated.Equal(time.Date(2023, 9, 5, 6, 0, 0, 0, time.UTC)))
+}
+
+func TestJiraErrorResponses(t *testing.T) {
+	tests := []struct {
+		name    string
+		code    int
+		body    string
+		wantErr error
+		wantMsg string
+	}{
+		{
+			name:    "bad jql",
+			code:    http.StatusBadRequest,
+			body:    `{"errorMessages": ["The value 'bob' does not exist for the field 'worklogAuthor'."], "errors": {}}`,
+			wantErr: ErrBadJQL,
+			wantMsg: "The value 'bob' does not exist for the field 'worklogAuthor'.",
+		},
+		{
+			name:    "unauthorized",
+			code:    http.StatusUnauthorized,
+			body:    `<html>Unauthorized</html>`,
+			wantErr: ErrUnauthorized,
+		},
+		{
+			name:    "forbidden",
+			code:    http.StatusForbidden,
+			body:    `{"errorMessages": [], "errors": {"jql": "no browse permission"}}`,
+			wantErr: ErrForbidden,
+			wantMsg: "jql: no browse permission",
+		},
+		{
+			name:    "not found",
+			code:    http.StatusNotFound,
+			wantErr: ErrNotFound,
+		},
+		{
+			name:    "rate limited",
+			code:    http.StatusTooManyRequests,
+			wantErr: ErrRateLimited,
+		},
+		{
+			name:    "server error",
+			code:    http.StatusInternalServerError,
+			wantErr: ErrUnexpectedStatus,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(tt.code)
+				_, _ = w.Write([]byte(tt.body))
+			}))
+			defer srv.Close()
+
+			jc, err := NewJiraCli(srv.Client(), config.Jira{
+				URL:   srv.URL,
+				Retry: config.Retry{MaxAttempts: 1},
+			}, nil)
+			require.NoError(t, err)
+
+			_, err = jc.UserWorkedIssuesByDate(context.Background(), "user1", time.Now(), "")
+			require.ErrorIs(t, err, tt.wantErr)
+
+			var je *JiraError
+			require.True(t, errors.As(err, &je))
+			require.Equal(t, tt.code, je.StatusCode)
+			if tt.wantMsg != "" {
+				require.Equal(t, []string{tt.wantMsg}, je.Messages)
+				require.ErrorContains(t, err, tt.wantMsg)
+			}
+		})
+	}
+
+	t.Run("worklogs not found", func(t *testing.T) {
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`{"errorMessages": ["Issue does not exist or you do not have permission to see it."]}`))
+		}))
+		defer srv.Close()
+
+		jc, err := NewJiraCli(srv.Client(), config.Jira{URL: srv.URL}, nil)
+		require.NoError(t, err)
+		day := time.Now()
+		_, err = jc.WorkLogsPerIssues(
+			context.Background(), "user1", day, day, []model.Issue{{ID: "1", Key: "PRJ-1"}},
+		)
+		require.ErrorIs(t, err, ErrNotFound)
+		require.ErrorContains(t, err, "fetching issue 'PRJ-1' worklogs")
+	})
+}
+
+func TestUserWorkedIssuesByDateScope(t *testing.T) {
+	date := time.Date(2023, 9, 1, 0, 0, 0, 0, time.UTC)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, `worklogDate=2023-09-01 AND worklogAuthor=user1 AND project in ("PRJ")`,
+			r.URL.Query().Get("jql"))
+		_, _ = w.Write([]byte(`{"issues":[{"id":"1","key":"PRJ-1"}]}`))
+	}))
+	defer srv.Close()
+
+	jc, err := NewJiraCli(srv.Client(), config.Jira{URL: srv.URL}, nil)
+	require.NoError(t, err)
+
+	issues, err := jc.UserWorkedIssuesByDate(context.Background(), "user1", date, `project in ("PRJ")`)
+	require.NoError(t, err)
+	require.Equal(t, []model.Issue{{ID: "1", Key: "PRJ-1"}}, issues)
+}
+
+func TestUserWorkedIssuesByDateFields(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "summary,project,parent,customfield_10100", r.URL.Query().Get("fields"))
+		_, _ = w.Write([]byte(`{"issues":[
+			{"id":"1","key":"PRJ-1","fields":{"summary":"Login page","project":{"key":"PRJ"},"customfield_10100":"PRJ-10"}},
+			{"id":"2","key":"PRJ-2","fields":{"summary":"Logout","project":{"key":"PRJ"},
+				"parent":{"key":"PRJ-20","fields":{"issuetype":{"name":"Epic"}}}}},
+			{"id":"3","key":"PRJ-3","fields":{"summary":"Subtask","project":{"key":"PRJ"},
+				"parent":{"key":"PRJ-2","fields":{"issuetype":{"name":"Story"}}}}}
+		]}`))
+	}))
+	defer srv.Close()
+
+	jc, err := NewJiraCli(srv.Client(), config.Jira{URL: srv.URL, EpicField: "customfield_10100"}, nil)
+	require.NoError(t, err)
+
+	issues, err := jc.UserWorkedIssuesByDate(context.Background(), "user1", time.Now(), "")
+	require.NoError(t, err)
+	require.Equal(t, []model.Issue{
+		{ID: "1", Key: "PRJ-1", Summary: "Login page", Project: "PRJ", Epic: "PRJ-10"},
+		{ID: "2", Key: "PRJ-2", Summary: "Logout", Project: "PRJ", Epic: "PRJ-20"},
+		{ID: "3", Key: "PRJ-3", Summary: "Subtask", Project: "PRJ"},
+	}, issues)
+}
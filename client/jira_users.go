@@ -0,0 +1,205 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"path"
+	"strconv"
+	"strings"
+
+	"github.com/duke0x/ts-notifier/model"
+)
+
+// jiraPageSize is a number of users requested per page
+const jiraPageSize = 50
+
+// jiraUser stores Jira user data as described in
+// https://developer.atlassian.com/cloud/jira/platform/rest/v2/api-group-users/
+type jiraUser struct {
+	AccountID    string `json:"accountId"`
+	AccountType  string `json:"accountType"`
+	DisplayName  string `json:"displayName"`
+	EmailAddress string `json:"emailAddress"`
+	Active       bool   `json:"active"`
+}
+
+func (u jiraUser) account() model.Account {
+	return model.Account{ID: u.AccountID, Name: u.DisplayName, Email: u.EmailAddress}
+}
+
+// human reports whether the user is an active person, not an app
+func (u jiraUser) human() bool {
+	return u.Active && (u.AccountType == "" || u.AccountType == "atlassian")
+}
+
+type groupMembersResponse struct {
+	IsLast bool       `json:"isLast"`
+	Values []jiraUser `json:"values"`
+}
+
+// GroupMembers returns active users of the Jira group
+func (wls *Jira) GroupMembers(ctx context.Context, group string) ([]model.Account, error) {
+	var accs []model.Account
+	for startAt := 0; ; startAt += jiraPageSize {
+		var rsp groupMembersResponse
+		err := wls.getJSON(ctx, "/rest/api/2/group/member", url.Values{
+			"groupname":  {group},
+			"startAt":    {strconv.Itoa(startAt)},
+			"maxResults": {strconv.Itoa(jiraPageSize)},
+		}, &rsp)
+		if err != nil {
+			return nil, fmt.Errorf("fetching group '%s' members: %w", group, err)
+		}
+
+		for _, u := range rsp.Values {
+			if u.human() {
+				accs = append(accs, u.account())
+			}
+		}
+		if rsp.IsLast || len(rsp.Values) == 0 {
+			return accs, nil
+		}
+	}
+}
+
+type projectRoleResponse struct {
+	Actors []struct {
+		Type      string `json:"type"`
+		ActorUser struct {
+			AccountID string `json:"accountId"`
+		} `json:"actorUser"`
+	} `json:"actors"`
+}
+
+// ProjectRoleMembers returns active users of the project role, e.g. 'Developers'.
+// Groups assigned to the role are not expanded.
+func (wls *Jira) ProjectRoleMembers(ctx context.Context, project, role string) ([]model.Account, error) {
+	rolesPath := "/rest/api/2/project/" + url.PathEscape(project) + "/role"
+
+	// project roles are listed as role name to role URL map
+	var roles map[string]string
+	if err := wls.getJSON(ctx, rolesPath, nil, &roles); err != nil {
+		return nil, fmt.Errorf("fetching project '%s' roles: %w", project, err)
+	}
+	roleURL, ok := roles[role]
+	if !ok {
+		return nil, fmt.Errorf("%w: project '%s' has no role '%s'", ErrNotFound, project, role)
+	}
+	u, err := url.Parse(roleURL)
+	if err != nil {
+		return nil, fmt.Errorf("parsing project role '%s' URL: %w", role, err)
+	}
+
+	// role URL host can differ from the configured one behind a proxy,
+	// so only the role ID is taken from it
+	var rsp projectRoleResponse
+	if err := wls.getJSON(ctx, rolesPath+"/"+path.Base(u.Path), nil, &rsp); err != nil {
+		return nil, fmt.Errorf("fetching project '%s' role '%s' actors: %w", project, role, err)
+	}
+
+	var accs []model.Account
+	for _, a := range rsp.Actors {
+		if a.Type != "atlassian-user-role-actor" || a.ActorUser.AccountID == "" {
+			continue
+		}
+		var user jiraUser
+		err := wls.getJSON(ctx, "/rest/api/2/user", url.Values{"accountId": {a.ActorUser.AccountID}}, &user)
+		if err != nil {
+			return nil, fmt.Errorf("fetching user '%s': %w", a.ActorUser.AccountID, err)
+		}
+		if user.human() {
+			accs = append(accs, user.account())
+		}
+	}
+
+	return accs, nil
+}
+
+// UserByEmail returns the active user with the email. The only user
+// with hidden email is accepted too, since the search matches hidden emails.
+// It returns ErrNotFound if no user or several users are found.
+func (wls *Jira) UserByEmail(ctx context.Context, email string) (model.Account, error) {
+	var users []jiraUser
+	if err := wls.getJSON(ctx, "/rest/api/2/user/search", url.Values{"query": {email}}, &users); err != nil {
+		return model.Account{}, fmt.Errorf("searching user '%s': %w", email, err)
+	}
+
+	var found []jiraUser
+	for _, u := range users {
+		if !u.human() {
+			continue
+		}
+		if strings.EqualFold(u.EmailAddress, email) {
+			return u.account(), nil
+		}
+		// email is hidden when the user restricts its visibility, users
+		// with other visible email match the query by name or partially
+		if u.EmailAddress == "" {
+			found = append(found, u)
+		}
+	}
+	if len(found) != 1 {
+		return model.Account{}, fmt.Errorf("%w: %d users match '%s'", ErrNotFound, len(found), email)
+	}
+
+	acc := found[0].account()
+	acc.Email = email
+
+	return acc, nil
+}
+
+// UserByName returns the only active user with the display name.
+// It returns ErrNotFound if no user or several users are found.
+func (wls *Jira) UserByName(ctx context.Context, name string) (model.Account, error) {
+	var users []jiraUser
+	if err := wls.getJSON(ctx, "/rest/api/2/user/search", url.Values{"query": {name}}, &users); err != nil {
+		return model.Account{}, fmt.Errorf("searching user '%s': %w", name, err)
+	}
+
+	var found []jiraUser
+	for _, u := range users {
+		if u.human() && strings.EqualFold(u.DisplayName, name) {
+			found = append(found, u)
+		}
+	}
+	if len(found) != 1 {
+		return model.Account{}, fmt.Errorf("%w: %d users named '%s'", ErrNotFound, len(found), name)
+	}
+
+	return found[0].account(), nil
+}
+
+// getJSON sends GET request to the Jira API path and decodes the response into out
+func (wls *Jira) getJSON(ctx context.Context, apiPath string, qp url.Values, out any) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, wls.config.URL+apiPath, nil)
+	if err != nil {
+		return fmt.Errorf("creating request: %w", err)
+	}
+	req.URL.RawQuery = qp.Encode()
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := wls.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("sending request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	rspData, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("reading response: %w", err)
+	}
+
+	if err := checkResponse(resp, rspData, ErrUnexpectedStatus); err != nil {
+		return err
+	}
+
+	if err := json.Unmarshal(rspData, out); err != nil {
+		return fmt.Errorf("decoding response: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,177 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/duke0x/ts-notifier/config"
+	"github.com/duke0x/ts-notifier/model"
+)
+
+func newUsersJira(t *testing.T) *Jira {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/rest/api/2/group/member", func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "backend-devs", r.URL.Query().Get("groupname"))
+		if r.URL.Query().Get("startAt") == "0" {
+			_, _ = w.Write([]byte(`{"isLast": false, "values": [
+				{"accountId": "acc1", "displayName": "Ivan Ivanov", "emailAddress": "ivanov@myorg.com", "active": true},
+				{"accountId": "app1", "displayName": "Automation", "accountType": "app", "active": true}
+			]}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"isLast": true, "values": [
+			{"accountId": "acc2", "displayName": "Petr Petrov", "active": true},
+			{"accountId": "acc3", "displayName": "Fired", "active": false}
+		]}`))
+	})
+	mux.HandleFunc("/rest/api/2/project/PRJ/role", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"Developers": "https://other.host/rest/api/2/project/10000/role/10002"}`))
+	})
+	mux.HandleFunc("/rest/api/2/project/PRJ/role/10002", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"actors": [
+			{"type": "atlassian-user-role-actor", "actorUser": {"accountId": "acc1"}},
+			{"type": "atlassian-group-role-actor", "displayName": "jira-users"}
+		]}`))
+	})
+	mux.HandleFunc("/rest/api/2/user", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = fmt.Fprintf(w, `{"accountId": %q, "displayName": "Ivan Ivanov", "active": true}`,
+			r.URL.Query().Get("accountId"))
+	})
+	mux.HandleFunc("/rest/api/2/user/search", func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Query().Get("query") {
+		case "ivanov@myorg.com":
+			_, _ = w.Write([]byte(`[{"accountId": "acc1", "displayName": "Ivan Ivanov", "active": true}]`))
+		case "petrov@myorg.com":
+			_, _ = w.Write([]byte(`[
+				{"accountId": "acc2", "displayName": "Petr Petrov", "emailAddress": "Petrov@myorg.com", "active": true},
+				{"accountId": "acc4", "displayName": "Petr Petrov Jr", "active": true}
+			]`))
+		case "petr@myorg.com":
+			_, _ = w.Write([]byte(`[
+				{"accountId": "acc2", "displayName": "Petr Petrov", "emailAddress": "Petrov@myorg.com", "active": true}
+			]`))
+		default:
+			_, _ = w.Write([]byte(`[]`))
+		}
+	})
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+
+	jc, err := NewJiraCli(srv.Client(), config.Jira{URL: srv.URL}, nil)
+	require.NoError(t, err)
+
+	return jc
+}
+
+func TestJira_GroupMembers(t *testing.T) {
+	got, err := newUsersJira(t).GroupMembers(context.Background(), "backend-devs")
+	require.NoError(t, err)
+	require.Equal(t, []model.Account{
+		{ID: "acc1", Name: "Ivan Ivanov", Email: "ivanov@myorg.com"},
+		{ID: "acc2", Name: "Petr Petrov"},
+	}, got)
+}
+
+func TestJira_ProjectRoleMembers(t *testing.T) {
+	jc := newUsersJira(t)
+
+	got, err := jc.ProjectRoleMembers(context.Background(), "PRJ", "Developers")
+	require.NoError(t, err)
+	require.Equal(t, []model.Account{{ID: "acc1", Name: "Ivan Ivanov"}}, got)
+
+	_, err = jc.ProjectRoleMembers(context.Background(), "PRJ", "Testers")
+	require.ErrorIs(t, err, ErrNotFound)
+}
+
+func TestJira_UserByEmail(t *testing.T) {
+	jc := newUsersJira(t)
+	ctx := context.Background()
+
+	got, err := jc.UserByEmail(ctx, "ivanov@myorg.com")
+	require.NoError(t, err)
+	require.Equal(t, model.Account{ID: "acc1", Name: "Ivan Ivanov", Email: "ivanov@myorg.com"}, got)
+
+	got, err = jc.UserByEmail(ctx, "petrov@myorg.com")
+	require.NoError(t, err)
+	require.Equal(t, "acc2", got.ID)
+
+	// the only user found has other visible email
+	_, err = jc.UserByEmail(ctx, "petr@myorg.com")
+	require.ErrorIs(t, err, ErrNotFound)
+
+	_, err = jc.UserByEmail(ctx, "nobody@myorg.com")
+	require.ErrorIs(t, err, ErrNotFound)
+}
+
+func TestMattermost_ChannelMembers(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "/api/v4/users", r.URL.Path)
+		require.Equal(t, "Bearer token", r.Header.Get("Authorization"))
+		if r.URL.Query().Get("in_channel") != "ch1" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		_, _ = w.Write([]byte(`[
+			{"id": "u1", "username": "ivanov.i", "email": "ivanov@myorg.com", "first_name": "Ivan", "last_name": "Ivanov"},
+			{"id": "u2", "username": "ts-bot", "is_bot": true},
+			{"id": "u3", "username": "gone", "delete_at": 1690000000000}
+		]`))
+	}))
+	defer srv.Close()
+
+	mm := NewNotifier(srv.Client(), config.Mattermost{URL: srv.URL, AuthToken: "token"}, nil)
+	got, err := mm.ChannelMembers(context.Background(), "ch1")
+	require.NoError(t, err)
+	require.Equal(t, []model.Account{
+		{ID: "u1", Name: "Ivan Ivanov", Username: "ivanov.i", Email: "ivanov@myorg.com"},
+	}, got)
+
+	_, err = mm.ChannelMembers(context.Background(), "ch2")
+	require.ErrorIs(t, err, ErrMattermostNotFound)
+}
+
+func TestJira_UserByName(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`[
+			{"accountId": "acc1", "displayName": "Ivan Ivanov", "active": true},
+			{"accountId": "acc2", "displayName": "Ivan Ivanovich", "active": true},
+			{"accountId": "acc3", "displayName": "Petr Petrov", "active": true},
+			{"accountId": "acc4", "displayName": "Petr Petrov", "active": true}
+		]`))
+	}))
+	defer srv.Close()
+
+	jc, err := NewJiraCli(srv.Client(), config.Jira{URL: srv.URL}, nil)
+	require.NoError(t, err)
+
+	got, err := jc.UserByName(context.Background(), "ivan ivanov")
+	require.NoError(t, err)
+	require.Equal(t, "acc1", got.ID)
+
+	_, err = jc.UserByName(context.Background(), "Petr Petrov")
+	require.ErrorIs(t, err, ErrNotFound)
+}
+
+func TestMattermost_UserByEmail(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v4/users/email/ivanov@myorg.com" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		_, _ = w.Write([]byte(`{"id": "u1", "username": "ivanov.i", "email": "ivanov@myorg.com"}`))
+	}))
+	defer srv.Close()
+
+	mm := NewNotifier(srv.Client(), config.Mattermost{URL: srv.URL}, nil)
+	got, err := mm.UserByEmail(context.Background(), "ivanov@myorg.com")
+	require.NoError(t, err)
+	require.Equal(t, "ivanov.i", got.Username)
+
+	_, err = mm.UserByEmail(context.Background(), "nobody@myorg.com")
+	require.ErrorIs(t, err, ErrMattermostNotFound)
+}
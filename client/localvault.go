@@ -0,0 +1,61 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/duke0x/ts-notifier/config"
+)
+
+// LocalVaultAddrScheme is a VAULT_ADDR scheme of the local secrets file,
+// e.g. 'file:///etc/ts-notifier/secrets.json'
+const LocalVaultAddrScheme = "file://"
+
+// LocalVault is a Vault stand-in reading secrets from a local JSON file,
+// e.g. for development without Vault server. Secrets are referenced like
+// Vault ones, the file maps secret paths to their data:
+//
+//	{"secret/data/ts-notifier": {"jira_token": "..."}}
+//
+// It implements config.SecretsProvider.
+type LocalVault struct {
+	path string
+}
+
+// NewLocalVault returns Vault stand-in reading secrets from the file
+func NewLocalVault(path string) *LocalVault {
+	return &LocalVault{path: path}
+}
+
+var _ config.SecretsProvider = (*LocalVault)(nil)
+
+func (v *LocalVault) Scheme() string {
+	return VaultScheme
+}
+
+// Secret returns the secret by '<path>#<key>' reference,
+// the file is read on every call to pick up its changes
+func (v *LocalVault) Secret(_ context.Context, ref string) (string, error) {
+	path, key, err := splitVaultRef(ref)
+	if err != nil {
+		return "", err
+	}
+
+	raw, err := os.ReadFile(v.path)
+	if err != nil {
+		return "", fmt.Errorf("reading secrets file: %w", err)
+	}
+	var secrets map[string]map[string]json.RawMessage
+	if err := json.Unmarshal(raw, &secrets); err != nil {
+		return "", fmt.Errorf("decoding secrets file: %w", err)
+	}
+
+	data, ok := secrets[path]
+	if !ok {
+		return "", fmt.Errorf("%w: vault path '%s'", config.ErrSecretNotFound, path)
+	}
+
+	return vaultSecret(data, path, key)
+}
@@ -0,0 +1,33 @@
+package client
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/duke0x/ts-notifier/config"
+)
+
+func TestLocalVault_Secret(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "secrets.json")
+	require.NoError(t, os.WriteFile(path, []byte(`{"secret/data/ts-notifier": {"jira_token": "local-secret"}}`), 0o600))
+
+	v := NewLocalVault(path)
+	ctx := context.Background()
+	got, err := v.Secret(ctx, "secret/data/ts-notifier#jira_token")
+	require.NoError(t, err)
+	require.Equal(t, "local-secret", got)
+
+	_, err = v.Secret(ctx, "secret/data/ts-notifier#mm_token")
+	require.ErrorIs(t, err, config.ErrSecretNotFound)
+	_, err = v.Secret(ctx, "secret/data/other#jira_token")
+	require.ErrorIs(t, err, config.ErrSecretNotFound)
+	_, err = v.Secret(ctx, "secret/data/ts-notifier")
+	require.ErrorContains(t, err, "bad vault secret reference")
+
+	_, err = NewLocalVault(filepath.Join(t.TempDir(), "missing.json")).Secret(ctx, "secret/data/ts-notifier#jira_token")
+	require.ErrorIs(t, err, os.ErrNotExist)
+}
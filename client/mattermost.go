@@ -6,25 +6,88 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
+	"log/slog"
 	"net/http"
+	"net/url"
 	"strings"
 	"time"
 
 	"github.com/duke0x/ts-notifier/config"
+	"github.com/duke0x/ts-notifier/model"
 )
 
+const defaultMattermostTimeout = 3 * time.Second
+
 type Mattermost struct {
 	client *http.Client
 	config config.Mattermost
+	logger *slog.Logger
 }
 
-func NewNotifier(client *http.Client, cfg config.Mattermost) *Mattermost {
-	return &Mattermost{client: client, config: cfg}
+func NewNotifier(client *http.Client, cfg config.Mattermost, logger *slog.Logger) *Mattermost {
+	logger = orDefault(logger)
+
+	client = withTimeout(withLogger(client, logger), cfg.Timeout, defaultMattermostTimeout)
+	client = withRetry(client, cfg.Retry, logger)
+
+	return &Mattermost{client: client, config: cfg, logger: logger}
 }
 
 type CreatePostRequest struct {
 	ChannelID string `json:"channel_id"`
-	Message   string `json:"message"`
+	// RootID is the thread root post identifier of a reply
+	RootID  string     `json:"root_id,omitempty"`
+	Message string     `json:"message"`
+	Props   *PostProps `json:"props,omitempty"`
+}
+
+// PatchPostRequest stores the post fields to update
+type PatchPostRequest struct {
+	Message string     `json:"message"`
+	Props   *PostProps `json:"props,omitempty"`
+}
+
+// PostProps stores the post rich content
+type PostProps struct {
+	Attachments []MessageAttachment `json:"attachments"`
+}
+
+// MessageAttachment is a post attachment as described in
+// https://developers.mattermost.com/integrate/reference/message-attachments/
+type MessageAttachment struct {
+	Fallback string             `json:"fallback,omitempty"`
+	Color    string             `json:"color,omitempty"`
+	Title    string             `json:"title,omitempty"`
+	Text     string             `json:"text,omitempty"`
+	Fields   []AttachmentField  `json:"fields,omitempty"`
+	Actions  []AttachmentAction `json:"actions,omitempty"`
+}
+
+type AttachmentField struct {
+	Title string `json:"title"`
+	Value string `json:"value"`
+	Short bool   `json:"short"`
+}
+
+// AttachmentAction is an interactive message button as described in
+// https://developers.mattermost.com/integrate/plugins/interactive-messages/
+type AttachmentAction struct {
+	ID          string            `json:"id"`
+	Name        string            `json:"name"`
+	Integration ActionIntegration `json:"integration"`
+}
+
+// ActionIntegration is a request Mattermost sends when the button is pressed
+type ActionIntegration struct {
+	URL     string            `json:"url"`
+	Context map[string]string `json:"context,omitempty"`
+}
+
+// statusColors are attachment colors by the message part status
+var statusColors = map[model.Status]string{
+	model.StatusOK:      "#3db887",
+	model.StatusWarning: "#ffbc1f",
+	model.StatusAlert:   "#d24b4e",
 }
 
 type CreatePostResponse struct {
@@ -47,19 +110,27 @@ type CreatePostResponse struct {
 	Participants  interface{} `json:"participants"`
 }
 
-func (c *Mattermost) Notify(channel, message string) error {
+func (c *Mattermost) Notify(ctx context.Context, channel, message string) error {
+	_, err := c.Post(ctx, channel, "", model.Message{Text: message})
+
+	return err
+}
+
+// Post sends the message to the channel and returns the created post identifier.
+// The message is a reply in the thread of the rootID post if it is not empty.
+func (c *Mattermost) Post(ctx context.Context, channel, rootID string, msg model.Message) (string, error) {
 	url := strings.Join([]string{c.config.URL, "/api/v4/posts"}, "")
 
+	message, props := c.render(msg)
 	cr := CreatePostRequest{
 		ChannelID: channel,
+		RootID:    rootID,
 		Message:   message,
+		Props:     props,
 	}
 	crData, _ := json.Marshal(cr)
 
 	// Create a new HTTP request
-	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second) //nolint:gomnd
-	defer cancel()
-
 	req, err := http.NewRequestWithContext(
 		ctx,
 		http.MethodPost,
@@ -67,7 +138,7 @@ func (c *Mattermost) Notify(channel, message string) error {
 		bytes.NewBuffer(crData),
 	)
 	if err != nil {
-		return fmt.Errorf("creating 'create post' request: %w", err)
+		return "", fmt.Errorf("creating 'create post' request: %w", err)
 	}
 
 	// Set headers
@@ -77,7 +148,7 @@ func (c *Mattermost) Notify(channel, message string) error {
 	// Send the request
 	resp, err := c.client.Do(req)
 	if err != nil {
-		return fmt.Errorf("sending 'post message to channel' request: %w", err)
+		return "", fmt.Errorf("sending 'post message to channel' request: %w", err)
 	}
 	defer func() {
 		_ = resp.Body.Close()
@@ -86,11 +157,11 @@ func (c *Mattermost) Notify(channel, message string) error {
 	// Parse the response
 	rspData, err := io.ReadAll(resp.Body)
 	if err != nil {
-		return fmt.Errorf("sending 'post message to channel' response: %w", err)
+		return "", fmt.Errorf("sending 'post message to channel' response: %w", err)
 	}
 
 	if resp.StatusCode != http.StatusCreated {
-		return fmt.Errorf(
+		return "", fmt.Errorf(
 			"mattermost return %d rsp code, expected %d response",
 			resp.StatusCode,
 			http.StatusCreated,
@@ -100,8 +171,88 @@ func (c *Mattermost) Notify(channel, message string) error {
 	var rsp CreatePostResponse
 	err = json.Unmarshal(rspData, &rsp)
 	if err != nil {
-		return fmt.Errorf("parsing 'post message to channel' response: %w", err)
+		return "", fmt.Errorf("parsing 'post message to channel' response: %w", err)
+	}
+	c.logger.DebugContext(ctx, "post created", "channel", channel, "post", rsp.ID)
+
+	return rsp.ID, nil
+}
+
+// NotifyUser sends the direct message to the user with the username
+// from the user of the notifier token
+func (c *Mattermost) NotifyUser(ctx context.Context, username, message string) error {
+	channel, err := c.directChannel(ctx, username)
+	if err != nil {
+		return fmt.Errorf("opening direct channel with '%s': %w", username, err)
+	}
+
+	return c.Notify(ctx, channel, message)
+}
+
+// UpdatePost replaces the message of the post
+func (c *Mattermost) UpdatePost(ctx context.Context, postID string, msg model.Message) error {
+	message, props := c.render(msg)
+	var rsp CreatePostResponse
+	if err := c.doJSON(ctx, http.MethodPut, "/api/v4/posts/"+url.PathEscape(postID)+"/patch", nil,
+		PatchPostRequest{Message: message, Props: props}, &rsp); err != nil {
+		return fmt.Errorf("updating post '%s': %w", postID, err)
 	}
+	c.logger.DebugContext(ctx, "post updated", "post", rsp.ID)
 
 	return nil
 }
+
+// render returns the post text and props of the message.
+// Attachments are sent only if they are enabled, otherwise the plain text is sent.
+// The message title is sent with the attachments, the text is sent if it has no title.
+func (c *Mattermost) render(msg model.Message) (string, *PostProps) {
+	if !c.config.Attachments || len(msg.Attachments) == 0 {
+		return msg.Content(false), nil
+	}
+
+	props := &PostProps{Attachments: make([]MessageAttachment, 0, len(msg.Attachments))}
+	for _, a := range msg.Attachments {
+		ma := MessageAttachment{
+			Fallback: a.Title,
+			Color:    statusColors[a.Status],
+			Title:    a.Title,
+			Text:     a.Text,
+		}
+		for _, f := range a.Fields {
+			ma.Fields = append(ma.Fields, AttachmentField{Title: f.Title, Value: f.Value, Short: f.Short})
+		}
+		for _, act := range a.Actions {
+			ma.Actions = append(ma.Actions, AttachmentAction{
+				ID:          act.ID,
+				Name:        act.Name,
+				Integration: ActionIntegration{URL: act.URL, Context: act.Context},
+			})
+		}
+		props.Attachments = append(props.Attachments, ma)
+	}
+
+	return msg.Content(true), props
+}
+
+// directChannel returns identifier of the direct messages channel
+// between the notifier user and the user, the channel is created if needed
+func (c *Mattermost) directChannel(ctx context.Context, username string) (string, error) {
+	var me mattermostUser
+	if err := c.getJSON(ctx, "/api/v4/users/me", nil, &me); err != nil {
+		return "", fmt.Errorf("fetching notifier user: %w", err)
+	}
+	user, err := c.UserByUsername(ctx, username)
+	if err != nil {
+		return "", err
+	}
+
+	var channel struct {
+		ID string `json:"id"`
+	}
+	if err := c.doJSON(ctx, http.MethodPost, "/api/v4/channels/direct", nil,
+		[]string{me.ID, user.ID}, &channel); err != nil {
+		return "", fmt.Errorf("creating direct channel: %w", err)
+	}
+
+	return channel.ID, nil
+}
@@ -1,9 +1,11 @@
 package client
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"github.com/duke0x/ts-notifier/config"
+	"github.com/duke0x/ts-notifier/model"
 	"github.com/stretchr/testify/require"
 	"io"
 	"net/http"
@@ -113,9 +115,9 @@ func TestMattermost_Notify(t *testing.T) {
 
 			nm := NewNotifier(srv.Client(), config.Mattermost{
 				URL: srv.URL,
-			})
+			}, nil)
 
-			err := nm.Notify(tt.args.channel, tt.args.message)
+			err := nm.Notify(context.Background(), tt.args.channel, tt.args.message)
 			if tt.wantErr == nil {
 				require.NoError(t, err)
 			} else {
@@ -124,3 +126,134 @@ func TestMattermost_Notify(t *testing.T) {
 		})
 	}
 }
+
+func TestMattermost_NotifyUser(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/v4/users/me", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"id":"bot1","username":"ts-bot"}`))
+	})
+	mux.HandleFunc("/api/v4/users/username/ivanov.i", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"id":"user1","username":"ivanov.i"}`))
+	})
+	mux.HandleFunc("/api/v4/channels/direct", func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, http.MethodPost, r.Method)
+		var ids []string
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&ids))
+		require.Equal(t, []string{"bot1", "user1"}, ids)
+		w.WriteHeader(http.StatusCreated)
+		_, _ = w.Write([]byte(`{"id":"dm1"}`))
+	})
+	var posted CreatePostRequest
+	mux.HandleFunc("/api/v4/posts", func(w http.ResponseWriter, r *http.Request) {
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&posted))
+		w.WriteHeader(http.StatusCreated)
+		_, _ = w.Write([]byte(`{"id":"post1"}`))
+	})
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	c := NewNotifier(srv.Client(), config.Mattermost{URL: srv.URL}, nil)
+	require.NoError(t, c.NotifyUser(context.Background(), "ivanov.i", "reminder"))
+	require.Equal(t, CreatePostRequest{ChannelID: "dm1", Message: "reminder"}, posted)
+
+	err := c.NotifyUser(context.Background(), "petrov.p", "reminder")
+	require.ErrorIs(t, err, ErrMattermostNotFound)
+}
+
+func TestMattermost_PostInThread(t *testing.T) {
+	var posted CreatePostRequest
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "/api/v4/posts", r.RequestURI)
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&posted))
+		w.WriteHeader(http.StatusCreated)
+		_, _ = w.Write([]byte(`{"id":"post2","root_id":"post1"}`))
+	}))
+	defer srv.Close()
+
+	c := NewNotifier(srv.Client(), config.Mattermost{URL: srv.URL}, nil)
+	id, err := c.Post(context.Background(), "ch1", "post1", model.Message{Text: "follow up"})
+	require.NoError(t, err)
+	require.Equal(t, "post2", id)
+	require.Equal(t, CreatePostRequest{ChannelID: "ch1", RootID: "post1", Message: "follow up"}, posted)
+}
+
+func TestMattermost_UpdatePost(t *testing.T) {
+	var patched PatchPostRequest
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.RequestURI != "/api/v4/posts/post1/patch" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		require.Equal(t, http.MethodPut, r.Method)
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&patched))
+		_, _ = w.Write([]byte(`{"id":"post1"}`))
+	}))
+	defer srv.Close()
+
+	c := NewNotifier(srv.Client(), config.Mattermost{URL: srv.URL}, nil)
+	require.NoError(t, c.UpdatePost(context.Background(), "post1", model.Message{Text: "updated report"}))
+	require.Equal(t, PatchPostRequest{Message: "updated report"}, patched)
+
+	err := c.UpdatePost(context.Background(), "post2", model.Message{Text: "updated report"})
+	require.ErrorIs(t, err, ErrMattermostNotFound)
+}
+
+func TestMattermost_PostAttachments(t *testing.T) {
+	var posted CreatePostRequest
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&posted))
+		w.WriteHeader(http.StatusCreated)
+		_, _ = w.Write([]byte(`{"id":"post1"}`))
+	}))
+	defer srv.Close()
+
+	msg := model.Message{
+		Text:  "plain report",
+		Title: "report",
+		Attachments: []model.Attachment{{
+			Status: model.StatusWarning,
+			Title:  "Ivan Ivanov",
+			Fields: []model.Field{{Title: "Осталось", Value: "3h0m0s", Short: true}},
+			Actions: []model.Action{{
+				ID:      "recheck",
+				Name:    "Перепроверить",
+				URL:     "https://ts-notifier.myorg.com/actions/recheck",
+				Context: map[string]string{"team": "team1"},
+			}},
+		}},
+	}
+
+	c := NewNotifier(srv.Client(), config.Mattermost{URL: srv.URL}, nil)
+	_, err := c.Post(context.Background(), "ch1", "", msg)
+	require.NoError(t, err)
+	require.Equal(t, CreatePostRequest{ChannelID: "ch1", Message: "plain report"}, posted)
+
+	c = NewNotifier(srv.Client(), config.Mattermost{URL: srv.URL, Attachments: true}, nil)
+	_, err = c.Post(context.Background(), "ch1", "", msg)
+	require.NoError(t, err)
+	require.Equal(t, CreatePostRequest{
+		ChannelID: "ch1",
+		Message:   "report",
+		Props: &PostProps{Attachments: []MessageAttachment{{
+			Fallback: "Ivan Ivanov",
+			Color:    "#ffbc1f",
+			Title:    "Ivan Ivanov",
+			Fields:   []AttachmentField{{Title: "Осталось", Value: "3h0m0s", Short: true}},
+			Actions: []AttachmentAction{{
+				ID:   "recheck",
+				Name: "Перепроверить",
+				Integration: ActionIntegration{
+					URL:     "https://ts-notifier.myorg.com/actions/recheck",
+					Context: map[string]string{"team": "team1"},
+				},
+			}},
+		}}},
+	}, posted)
+
+	// the text is sent with the attachments if the message has no title
+	msg.Title = ""
+	_, err = c.Post(context.Background(), "ch1", "", msg)
+	require.NoError(t, err)
+	require.Equal(t, "plain report", posted.Message)
+	require.Len(t, posted.Props.Attachments, 1)
+}
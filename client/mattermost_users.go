@@ -0,0 +1,147 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/duke0x/ts-notifier/model"
+)
+
+// mattermostPageSize is a number of users requested per page
+const mattermostPageSize = 200
+
+var ErrMattermostNotFound = errors.New("mattermost resource not found")
+
+// mattermostUser stores Mattermost user data as described in
+// https://api.mattermost.com/#tag/users
+type mattermostUser struct {
+	ID        string `json:"id"`
+	Username  string `json:"username"`
+	Email     string `json:"email"`
+	FirstName string `json:"first_name"`
+	LastName  string `json:"last_name"`
+	DeleteAt  int64  `json:"delete_at"`
+	IsBot     bool   `json:"is_bot"`
+}
+
+func (u mattermostUser) account() model.Account {
+	name := strings.TrimSpace(u.FirstName + " " + u.LastName)
+	if name == "" {
+		name = u.Username
+	}
+
+	return model.Account{ID: u.ID, Name: name, Username: u.Username, Email: u.Email}
+}
+
+// ChannelMembers returns active users of the channel, bots are skipped
+func (c *Mattermost) ChannelMembers(ctx context.Context, channel string) ([]model.Account, error) {
+	var accs []model.Account
+	for page := 0; ; page++ {
+		var users []mattermostUser
+		err := c.getJSON(ctx, "/api/v4/users", url.Values{
+			"in_channel": {channel},
+			"page":       {strconv.Itoa(page)},
+			"per_page":   {strconv.Itoa(mattermostPageSize)},
+		}, &users)
+		if err != nil {
+			return nil, fmt.Errorf("fetching channel '%s' members: %w", channel, err)
+		}
+
+		for _, u := range users {
+			if u.DeleteAt == 0 && !u.IsBot {
+				accs = append(accs, u.account())
+			}
+		}
+		if len(users) < mattermostPageSize {
+			return accs, nil
+		}
+	}
+}
+
+// UserByEmail returns the user with the email.
+// It returns ErrMattermostNotFound if there is no such user.
+func (c *Mattermost) UserByEmail(ctx context.Context, email string) (model.Account, error) {
+	var user mattermostUser
+	if err := c.getJSON(ctx, "/api/v4/users/email/"+url.PathEscape(email), nil, &user); err != nil {
+		return model.Account{}, fmt.Errorf("fetching user '%s': %w", email, err)
+	}
+
+	return user.account(), nil
+}
+
+// UserByUsername returns the user with the username.
+// It returns ErrMattermostNotFound if there is no such user.
+func (c *Mattermost) UserByUsername(ctx context.Context, username string) (model.Account, error) {
+	var user mattermostUser
+	if err := c.getJSON(ctx, "/api/v4/users/username/"+url.PathEscape(username), nil, &user); err != nil {
+		return model.Account{}, fmt.Errorf("fetching user '%s': %w", username, err)
+	}
+
+	return user.account(), nil
+}
+
+// getJSON sends GET request to the Mattermost API path and decodes the response into out
+func (c *Mattermost) getJSON(ctx context.Context, apiPath string, qp url.Values, out any) error {
+	return c.doJSON(ctx, http.MethodGet, apiPath, qp, nil, out)
+}
+
+// doJSON sends request with JSON encoded body, if it is not nil,
+// to the Mattermost API path and decodes the response into out
+func (c *Mattermost) doJSON(ctx context.Context, method, apiPath string, qp url.Values, body, out any) error {
+	var reqBody io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return fmt.Errorf("encoding request: %w", err)
+		}
+		reqBody = bytes.NewReader(data)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, c.config.URL+apiPath, reqBody)
+	if err != nil {
+		return fmt.Errorf("creating request: %w", err)
+	}
+	req.URL.RawQuery = qp.Encode()
+	req.Header.Set("Accept", "application/json")
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	req.Header.Set("Authorization", "Bearer "+c.config.AuthToken)
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("sending request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	rspData, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("reading response: %w", err)
+	}
+
+	switch resp.StatusCode {
+	case http.StatusOK, http.StatusCreated:
+	case http.StatusNotFound:
+		return ErrMattermostNotFound
+	default:
+		return fmt.Errorf(
+			"mattermost return %d rsp code, expected %d response",
+			resp.StatusCode,
+			http.StatusOK,
+		)
+	}
+
+	if err := json.Unmarshal(rspData, out); err != nil {
+		return fmt.Errorf("decoding response: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,331 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"math/rand"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/duke0x/ts-notifier/config"
+)
+
+const redacted = "REDACTED"
+
+// sensitiveHeaders are request headers which values are never logged
+var sensitiveHeaders = []string{"Authorization", "Cookie", "X-Vault-Token"}
+
+// sensitiveParams are query parameters name parts which values are never logged
+var sensitiveParams = []string{"token", "secret", "password", "key"}
+
+// withLogger returns a copy of the http client which logs
+// every request on debug level with the secrets redacted
+func withLogger(client *http.Client, logger *slog.Logger) *http.Client {
+	c := *client
+	c.Transport = &loggingTransport{next: client.Transport, logger: logger}
+
+	return &c
+}
+
+// orDefault returns the default logger if logger is nil
+func orDefault(logger *slog.Logger) *slog.Logger {
+	if logger == nil {
+		return slog.Default()
+	}
+
+	return logger
+}
+
+// loggingTransport logs requests and responses on debug level
+type loggingTransport struct {
+	next   http.RoundTripper
+	logger *slog.Logger
+}
+
+func (t *loggingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	next := t.next
+	if next == nil {
+		next = http.DefaultTransport
+	}
+
+	ctx := req.Context()
+	if !t.logger.Enabled(ctx, slog.LevelDebug) {
+		return next.RoundTrip(req)
+	}
+
+	start := time.Now()
+	resp, err := next.RoundTrip(req)
+	attrs := []any{
+		"method", req.Method,
+		"url", redactURL(req.URL),
+		"headers", redactHeaders(req.Header),
+		"duration", time.Since(start),
+	}
+	if err != nil {
+		t.logger.DebugContext(ctx, "http request failed", append(attrs, "error", err)...)
+		return nil, err
+	}
+
+	t.logger.DebugContext(ctx, "http request sent", append(attrs, "status", resp.StatusCode)...)
+
+	return resp, nil
+}
+
+// redactURL returns URL string with sensitive query parameters
+// and user password redacted
+func redactURL(u *url.URL) string {
+	ru := *u
+	if _, ok := ru.User.Password(); ok {
+		ru.User = url.UserPassword(ru.User.Username(), redacted)
+	}
+
+	qp := ru.Query()
+	for name := range qp {
+		for _, sp := range sensitiveParams {
+			if strings.Contains(strings.ToLower(name), sp) {
+				qp.Set(name, redacted)
+			}
+		}
+	}
+	ru.RawQuery = qp.Encode()
+
+	return ru.String()
+}
+
+// redactHeaders returns request headers with sensitive values redacted
+func redactHeaders(h http.Header) map[string]string {
+	rh := make(map[string]string, len(h))
+	for name := range h {
+		rh[name] = h.Get(name)
+	}
+	for _, name := range sensitiveHeaders {
+		if h.Get(name) != "" {
+			rh[name] = redacted
+		}
+	}
+
+	return rh
+}
+
+// withTimeout returns a copy of the http client which request attempts
+// are limited by the timeout or by def if timeout is not set.
+// Wrapped with retries it limits every attempt, not the whole request.
+func withTimeout(client *http.Client, timeout, def time.Duration) *http.Client {
+	if timeout == 0 {
+		timeout = def
+	}
+
+	c := *client
+	c.Transport = &timeoutTransport{next: client.Transport, timeout: timeout}
+
+	return &c
+}
+
+// timeoutTransport limits the request time until its response body is closed
+type timeoutTransport struct {
+	next    http.RoundTripper
+	timeout time.Duration
+}
+
+func (t *timeoutTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	next := t.next
+	if next == nil {
+		next = http.DefaultTransport
+	}
+
+	ctx, cancel := context.WithTimeout(req.Context(), t.timeout)
+	resp, err := next.RoundTrip(req.WithContext(ctx))
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+	resp.Body = &cancelBody{ReadCloser: resp.Body, cancel: cancel}
+
+	return resp, nil
+}
+
+// cancelBody cancels the request context when the response body is closed
+type cancelBody struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+func (b *cancelBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.cancel()
+
+	return err
+}
+
+// Default retry settings
+const (
+	defaultMaxAttempts    = 3
+	defaultInitialBackoff = 200 * time.Millisecond
+	defaultMaxBackoff     = 10 * time.Second
+	defaultMaxRetryAfter  = time.Minute
+)
+
+// withRetry returns a copy of the http client which retries
+// failed requests according to the retry settings
+func withRetry(client *http.Client, cfg config.Retry, logger *slog.Logger) *http.Client {
+	if cfg.MaxAttempts == 0 {
+		cfg.MaxAttempts = defaultMaxAttempts
+	}
+	if cfg.InitialBackoff == 0 {
+		cfg.InitialBackoff = defaultInitialBackoff
+	}
+	if cfg.MaxBackoff == 0 {
+		cfg.MaxBackoff = defaultMaxBackoff
+	}
+	if cfg.MaxRetryAfter == 0 {
+		cfg.MaxRetryAfter = defaultMaxRetryAfter
+	}
+
+	c := *client
+	c.Transport = &retryTransport{next: client.Transport, config: cfg, logger: logger}
+
+	return &c
+}
+
+// retryTransport retries requests rejected by rate limits, and idempotent
+// requests failed with network or server errors, with jittered exponential backoff.
+// It honors Retry-After response header and gives up if the delay
+// is longer than MaxRetryAfter or does not fit in the request context deadline.
+type retryTransport struct {
+	next   http.RoundTripper
+	config config.Retry
+	logger *slog.Logger
+}
+
+func (t *retryTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	next := t.next
+	if next == nil {
+		next = http.DefaultTransport
+	}
+
+	ctx := req.Context()
+	for attempt := 1; ; attempt++ {
+		resp, err := next.RoundTrip(req)
+		if attempt >= t.config.MaxAttempts || !shouldRetry(req, resp, err) {
+			return resp, err
+		}
+
+		wait := t.backoff(attempt)
+		if err == nil {
+			if ra, ok := retryAfter(resp, time.Now()); ok {
+				if ra > t.config.MaxRetryAfter {
+					return resp, nil
+				}
+				wait = ra
+			}
+		}
+		if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) < wait {
+			return resp, err
+		}
+
+		attrs := []any{"method", req.Method, "url", redactURL(req.URL), "attempt", attempt}
+		if err != nil {
+			attrs = append(attrs, "error", err)
+		} else {
+			attrs = append(attrs, "status", resp.StatusCode)
+			_, _ = io.Copy(io.Discard, resp.Body)
+			_ = resp.Body.Close()
+		}
+		t.logger.WarnContext(ctx, "retrying http request", append(attrs, "wait", wait)...)
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, ctx.Err()
+		case <-timer.C:
+		}
+
+		if req, err = rewind(req); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// backoff returns a jittered delay before the retry after the attempt
+func (t *retryTransport) backoff(attempt int) time.Duration {
+	d := t.config.InitialBackoff << (attempt - 1)
+	if d <= 0 || d > t.config.MaxBackoff {
+		d = t.config.MaxBackoff
+	}
+
+	// wait at least half of the delay
+	return d/2 + time.Duration(rand.Int63n(int64(d/2)+1))
+}
+
+// shouldRetry reports whether the request can be sent again.
+// Rate limited non-idempotent requests are retried only if the server
+// asks to retry them later with Retry-After header.
+func shouldRetry(req *http.Request, resp *http.Response, err error) bool {
+	if err != nil {
+		return req.Context().Err() == nil && isIdempotent(req.Method)
+	}
+
+	switch {
+	case resp.StatusCode == http.StatusTooManyRequests:
+		return isIdempotent(req.Method) || resp.Header.Get("Retry-After") != ""
+	case resp.StatusCode >= http.StatusInternalServerError &&
+		resp.StatusCode != http.StatusNotImplemented:
+		return isIdempotent(req.Method)
+	default:
+		return false
+	}
+}
+
+func isIdempotent(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions,
+		http.MethodPut, http.MethodDelete:
+		return true
+	default:
+		return false
+	}
+}
+
+// retryAfter parses Retry-After header in seconds or HTTP date format
+func retryAfter(resp *http.Response, now time.Time) (time.Duration, bool) {
+	v := resp.Header.Get("Retry-After")
+	if v == "" {
+		return 0, false
+	}
+
+	if sec, err := strconv.Atoi(v); err == nil && sec >= 0 {
+		return time.Duration(sec) * time.Second, true
+	}
+
+	if t, err := http.ParseTime(v); err == nil {
+		return max(t.Sub(now), 0), true
+	}
+
+	return 0, false
+}
+
+// rewind returns the request copy with the body ready to be sent again
+func rewind(req *http.Request) (*http.Request, error) {
+	if req.Body == nil || req.Body == http.NoBody {
+		return req, nil
+	}
+	if req.GetBody == nil {
+		return nil, errors.New("request body can not be sent again")
+	}
+
+	body, err := req.GetBody()
+	if err != nil {
+		return nil, fmt.Errorf("rewinding request body: %w", err)
+	}
+	r := req.Clone(req.Context())
+	r.Body = body
+
+	return r, nil
+}
@@ -0,0 +1,254 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/duke0x/ts-notifier/config"
+)
+
+func TestLoggingTransportRedactsSecrets(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "Bearer secret-token", r.Header.Get("Authorization"))
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer srv.Close()
+
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewTextHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug}))
+	c := withLogger(srv.Client(), logger)
+
+	req, err := http.NewRequestWithContext(
+		context.Background(),
+		http.MethodGet,
+		srv.URL+"/api?access_token=secret-param&day=20230901",
+		nil,
+	)
+	require.NoError(t, err)
+	req.Header.Set("Authorization", "Bearer secret-token")
+
+	resp, err := c.Do(req)
+	require.NoError(t, err)
+	_ = resp.Body.Close()
+
+	log := buf.String()
+	require.Contains(t, log, "http request sent")
+	require.Contains(t, log, "status=200")
+	require.Contains(t, log, "day=20230901")
+	require.NotContains(t, log, "secret-token")
+	require.NotContains(t, log, "secret-param")
+}
+
+func TestLoggingTransportSkipsOnInfoLevel(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer srv.Close()
+
+	var buf bytes.Buffer
+	c := withLogger(srv.Client(), slog.New(slog.NewTextHandler(&buf, nil)))
+
+	resp, err := c.Get(srv.URL) //nolint:noctx
+	require.NoError(t, err)
+	_ = resp.Body.Close()
+	require.Empty(t, buf.String())
+}
+
+func TestRetryTransport(t *testing.T) {
+	retry := config.Retry{
+		MaxAttempts:    3,
+		InitialBackoff: time.Millisecond,
+		MaxBackoff:     5 * time.Millisecond,
+	}
+
+	tests := []struct {
+		name         string
+		method       string
+		codes        []int
+		retryAfter   string
+		wantCode     int
+		wantAttempts int
+	}{
+		{"success", http.MethodGet, []int{200}, "", 200, 1},
+		{"get retried on 503", http.MethodGet, []int{503, 502, 200}, "", 200, 3},
+		{"get attempts exhausted", http.MethodGet, []int{503, 503, 503, 200}, "", 503, 3},
+		{"post not retried on 503", http.MethodPost, []int{503, 201}, "", 503, 1},
+		{"get retried on 429", http.MethodGet, []int{429, 200}, "", 200, 2},
+		{"post retried on 429 with retry after", http.MethodPost, []int{429, 201}, "0", 201, 2},
+		{"post not retried on 429", http.MethodPost, []int{429, 201}, "", 429, 1},
+		{"bad request not retried", http.MethodGet, []int{400, 200}, "", 400, 1},
+		{"not implemented not retried", http.MethodGet, []int{501, 200}, "", 501, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var attempts int32
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				n := atomic.AddInt32(&attempts, 1)
+				body, _ := io.ReadAll(r.Body)
+				if r.Method == http.MethodPost {
+					require.Equal(t, "payload", string(body))
+				}
+				if tt.retryAfter != "" {
+					w.Header().Set("Retry-After", tt.retryAfter)
+				}
+				w.WriteHeader(tt.codes[n-1])
+			}))
+			defer srv.Close()
+
+			c := withRetry(srv.Client(), retry, slog.Default())
+			req, err := http.NewRequestWithContext(
+				context.Background(),
+				tt.method,
+				srv.URL,
+				strings.NewReader("payload"),
+			)
+			require.NoError(t, err)
+
+			resp, err := c.Do(req)
+			require.NoError(t, err)
+			_ = resp.Body.Close()
+			require.Equal(t, tt.wantCode, resp.StatusCode)
+			require.Equal(t, int32(tt.wantAttempts), atomic.LoadInt32(&attempts))
+		})
+	}
+}
+
+func TestRetryTransportCanceled(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer srv.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	time.AfterFunc(20*time.Millisecond, cancel)
+
+	c := withRetry(srv.Client(), config.Retry{
+		MaxAttempts:    10,
+		InitialBackoff: time.Second,
+		MaxBackoff:     time.Second,
+	}, slog.Default())
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, srv.URL, nil)
+	require.NoError(t, err)
+
+	_, err = c.Do(req) //nolint:bodyclose
+	require.ErrorIs(t, err, context.Canceled)
+}
+
+func TestRetryTransportRetryAfterDeadline(t *testing.T) {
+	var attempts int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&attempts, 1)
+		w.Header().Set("Retry-After", "10")
+		w.WriteHeader(http.StatusTooManyRequests)
+	}))
+	defer srv.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	// the delay is not limited by max backoff, but it does not fit in the deadline
+	c := withRetry(srv.Client(), config.Retry{
+		MaxAttempts:    3,
+		InitialBackoff: time.Millisecond,
+		MaxBackoff:     time.Millisecond,
+	}, slog.Default())
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, srv.URL, nil)
+	require.NoError(t, err)
+
+	resp, err := c.Do(req)
+	require.NoError(t, err)
+	_ = resp.Body.Close()
+	require.Equal(t, http.StatusTooManyRequests, resp.StatusCode)
+	require.Equal(t, int32(1), atomic.LoadInt32(&attempts))
+}
+
+func TestRetryTransportRetryAfterLimit(t *testing.T) {
+	var attempts int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&attempts, 1)
+		w.Header().Set("Retry-After", "86400")
+		w.WriteHeader(http.StatusTooManyRequests)
+	}))
+	defer srv.Close()
+
+	// the delay is too long to wait for without the deadline
+	c := withRetry(srv.Client(), config.Retry{}, slog.Default())
+	req, err := http.NewRequestWithContext(context.Background(), http.MethodGet, srv.URL, nil)
+	require.NoError(t, err)
+
+	resp, err := c.Do(req)
+	require.NoError(t, err)
+	_ = resp.Body.Close()
+	require.Equal(t, http.StatusTooManyRequests, resp.StatusCode)
+	require.Equal(t, int32(1), atomic.LoadInt32(&attempts))
+}
+
+func TestWithTimeoutLimitsAttempts(t *testing.T) {
+	var attempts int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&attempts, 1) == 1 {
+			<-r.Context().Done()
+			return
+		}
+		_, _ = w.Write([]byte("ok"))
+	}))
+	defer srv.Close()
+
+	c := withTimeout(srv.Client(), 50*time.Millisecond, time.Minute)
+	c = withRetry(c, config.Retry{
+		MaxAttempts:    3,
+		InitialBackoff: time.Millisecond,
+		MaxBackoff:     time.Millisecond,
+	}, slog.Default())
+
+	// the hung first attempt is timed out and retried
+	resp, err := c.Get(srv.URL) //nolint:noctx
+	require.NoError(t, err)
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	_ = resp.Body.Close()
+	require.Equal(t, "ok", string(body))
+	require.Equal(t, int32(2), atomic.LoadInt32(&attempts))
+
+	tt, ok := withTimeout(srv.Client(), 0, time.Minute).Transport.(*timeoutTransport)
+	require.True(t, ok)
+	require.Equal(t, time.Minute, tt.timeout)
+}
+
+func Test_retryAfter(t *testing.T) {
+	now := time.Date(2023, 9, 1, 10, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name   string
+		header string
+		want   time.Duration
+		wantOk bool
+	}{
+		{"no header", "", 0, false},
+		{"seconds", "120", 2 * time.Minute, true},
+		{"http date", now.Add(30 * time.Second).Format(http.TimeFormat), 30 * time.Second, true},
+		{"past http date", now.Add(-time.Minute).Format(http.TimeFormat), 0, true},
+		{"garbage", "soon", 0, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp := &http.Response{Header: http.Header{}}
+			if tt.header != "" {
+				resp.Header.Set("Retry-After", tt.header)
+			}
+			got, ok := retryAfter(resp, now)
+			require.Equal(t, tt.wantOk, ok)
+			require.Equal(t, tt.want, got)
+		})
+	}
+}
@@ -0,0 +1,136 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/duke0x/ts-notifier/config"
+)
+
+// VaultScheme is a prefix of config references to Vault secrets,
+// e.g. 'vault:secret/data/ts-notifier#jira_token'
+const VaultScheme = "vault"
+
+const defaultVaultTimeout = 10 * time.Second
+
+var ErrNoVaultAddr = errors.New("vault address is not set")
+
+// Vault reads secrets from HashiCorp Vault compatible KV secrets engine
+// of version 1 or 2. It implements config.SecretsProvider.
+type Vault struct {
+	client *http.Client
+	addr   string
+	token  string
+	logger *slog.Logger
+}
+
+// NewVault returns Vault secrets provider, addr and token are
+// usually taken from VAULT_ADDR and VAULT_TOKEN environment variables
+func NewVault(client *http.Client, addr, token string, logger *slog.Logger) *Vault {
+	logger = orDefault(logger)
+	client = withTimeout(withLogger(client, logger), 0, defaultVaultTimeout)
+
+	return &Vault{
+		client: client,
+		addr:   strings.TrimSuffix(addr, "/"),
+		token:  token,
+		logger: logger,
+	}
+}
+
+var _ config.SecretsProvider = (*Vault)(nil)
+
+func (v *Vault) Scheme() string {
+	return VaultScheme
+}
+
+type vaultResponse struct {
+	Data map[string]json.RawMessage `json:"data"`
+}
+
+// Secret returns the secret by '<path>#<key>' reference,
+// e.g. 'secret/data/ts-notifier#jira_token'
+func (v *Vault) Secret(ctx context.Context, ref string) (string, error) {
+	if v.addr == "" {
+		return "", ErrNoVaultAddr
+	}
+	path, key, err := splitVaultRef(ref)
+	if err != nil {
+		return "", err
+	}
+
+	url := strings.Join([]string{v.addr, "/v1/", path}, "")
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return "", fmt.Errorf("creating 'read secret' request: %w", err)
+	}
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("X-Vault-Token", v.token)
+
+	resp, err := v.client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("sending 'read secret' request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	rspData, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", fmt.Errorf("reading 'read secret' response: %w", err)
+	}
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+	case http.StatusNotFound:
+		return "", fmt.Errorf("%w: vault path '%s'", config.ErrSecretNotFound, path)
+	default:
+		return "", fmt.Errorf("vault returned %d status", resp.StatusCode)
+	}
+
+	var vr vaultResponse
+	if err := json.Unmarshal(rspData, &vr); err != nil {
+		return "", fmt.Errorf("decoding 'read secret' response: %w", err)
+	}
+
+	data := vr.Data
+	// KV version 2 engine nests the secret data
+	if nested, ok := vr.Data["data"]; ok {
+		var kv2 map[string]json.RawMessage
+		if err := json.Unmarshal(nested, &kv2); err == nil {
+			data = kv2
+		}
+	}
+
+	return vaultSecret(data, path, key)
+}
+
+// splitVaultRef splits '<path>#<key>' secret reference
+func splitVaultRef(ref string) (path, key string, err error) {
+	path, key, ok := strings.Cut(ref, "#")
+	path = strings.TrimPrefix(path, "/")
+	if !ok || path == "" || key == "" {
+		return "", "", fmt.Errorf("bad vault secret reference '%s', expected '<path>#<key>'", ref)
+	}
+
+	return path, key, nil
+}
+
+// vaultSecret returns the string secret by key from the secret data
+func vaultSecret(data map[string]json.RawMessage, path, key string) (string, error) {
+	raw, ok := data[key]
+	if !ok {
+		return "", fmt.Errorf("%w: vault path '%s' has no key '%s'", config.ErrSecretNotFound, path, key)
+	}
+	var secret string
+	if err := json.Unmarshal(raw, &secret); err != nil {
+		return "", fmt.Errorf("vault secret '%s' is not a string: %w", key, err)
+	}
+
+	return secret, nil
+}
@@ -0,0 +1,64 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/duke0x/ts-notifier/config"
+)
+
+func TestVault_Secret(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Vault-Token") != "root" {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		switch r.URL.Path {
+		case "/v1/secret/data/ts-notifier":
+			_, _ = w.Write([]byte(`{"data": {"data": {"jira_token": "kv2-secret"}, "metadata": {"version": 1}}}`))
+		case "/v1/kv/ts-notifier":
+			_, _ = w.Write([]byte(`{"data": {"jira_token": "kv1-secret"}}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer srv.Close()
+
+	tests := []struct {
+		name    string
+		token   string
+		ref     string
+		want    string
+		wantErr error
+	}{
+		{name: "kv version 2", token: "root", ref: "secret/data/ts-notifier#jira_token", want: "kv2-secret"},
+		{name: "kv version 1", token: "root", ref: "kv/ts-notifier#jira_token", want: "kv1-secret"},
+		{name: "no key", token: "root", ref: "kv/ts-notifier#mm_token", wantErr: config.ErrSecretNotFound},
+		{name: "no path", token: "root", ref: "kv/other#jira_token", wantErr: config.ErrSecretNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			v := NewVault(srv.Client(), srv.URL+"/", tt.token, nil)
+			got, err := v.Secret(context.Background(), tt.ref)
+			if tt.wantErr != nil {
+				require.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.want, got)
+		})
+	}
+
+	_, err := NewVault(srv.Client(), srv.URL, "bad", nil).Secret(context.Background(), "kv/ts-notifier#jira_token")
+	require.ErrorContains(t, err, "vault returned 403 status")
+
+	_, err = NewVault(srv.Client(), srv.URL, "root", nil).Secret(context.Background(), "kv/ts-notifier")
+	require.ErrorContains(t, err, "bad vault secret reference")
+
+	_, err = NewVault(srv.Client(), "", "root", nil).Secret(context.Background(), "kv/ts-notifier#jira_token")
+	require.ErrorIs(t, err, ErrNoVaultAddr)
+}
@@ -1,34 +1,135 @@
 package config
 
 import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
 	"errors"
 	"flag"
 	"fmt"
-	"io"
 	"os"
+	"reflect"
+	"strings"
 	"time"
 
 	"gopkg.in/yaml.v3"
 )
 
-var ErrBadDayFormat = errors.New("bad day format")
+var (
+	ErrBadDayFormat     = errors.New("bad day format")
+	ErrUnknownCommand   = errors.New("unknown command")
+	ErrBadPeriod        = errors.New("period start is after its end")
+	ErrNoUser           = errors.New("user is required, set it with -user")
+	ErrMemberNotAllowed = errors.New("-member is not supported by the command")
+)
+
+// Commands supported by the utility. CmdNotify is the default one
+// and runs when no command is given.
+const (
+	CmdNotify = ""
+	CmdStats  = "stats"
+	CmdLate   = "late"
+	CmdHTTP   = "http"
+	CmdMe     = "me"
+
+	CmdValidate       = "validate"
+	CmdResolveMembers = "resolve-members"
+)
+
+const dayFormat = "2006-01-02"
 
-// Params stores Jira, Notifier (Mattermost) and Teams parameters
+// Params stores Jira, IsDayOff, Notifier (Mattermost), Teams, HTTP Server and Log parameters
 type Params struct {
 	Jira     `yaml:"jira"`
+	IsDayOff `yaml:"isdayoff"`
 	Notifier `yaml:"notifier"`
 	Teams    `yaml:"teams"`
+	Server   `yaml:"server"`
+	Log      `yaml:"log"`
+	State    `yaml:"state"`
 }
 
+// State stores the tool state settings
+type State struct {
+	// Path is a JSON file keeping state between runs, e.g. members
+	// missed days for escalations. State is not kept if it is empty.
+	Path string `yaml:"path"`
+}
+
+// Jira authentication modes
+const (
+	JiraAuthBasic  = "basic"
+	JiraAuthBearer = "bearer"
+	JiraAuthOAuth2 = "oauth2"
+)
+
 // Jira stores Jira URL and access credentials
 type Jira struct {
 	URL string `yaml:"url"`
 
+	// AuthMode is a Jira authentication mode:
+	// JiraAuthBasic (default) sends UserEmail and AuthToken, it is used by Jira Cloud;
+	// JiraAuthBearer sends AuthToken as a Jira Server/Data Center Personal Access Token;
+	// JiraAuthOAuth2 obtains access tokens with OAuth2 client credentials.
+	AuthMode string `yaml:"auth_mode"`
+
 	UserEmail string `yaml:"user_email"`
 
 	// AuthToken is a Jira authentication token
 	// https://confluence.atlassian.com/enterprise/using-personal-access-tokens-1026032365.html
-	AuthToken string `yaml:"auth_token"`
+	AuthToken string `yaml:"auth_token" secret:"true"`
+
+	// OAuth2 stores OAuth2 client credentials for JiraAuthOAuth2 mode
+	OAuth2 OAuth2 `yaml:"oauth2"`
+
+	// EpicField is an issue field storing epic key ('Epic Link'),
+	// issue parent of 'Epic' type is used too. Default: customfield_10014.
+	EpicField string `yaml:"epic_field"`
+
+	// Timeout limits every Jira request attempt time. Default: 30s.
+	Timeout time.Duration `yaml:"timeout"`
+	// Retry stores Jira requests retry settings
+	Retry Retry `yaml:"retry"`
+}
+
+// OAuth2 stores OAuth2 client credentials grant settings
+type OAuth2 struct {
+	// TokenURL is an authorization server token endpoint,
+	// e.g. https://auth.atlassian.com/oauth/token
+	TokenURL string `yaml:"token_url"`
+	// ClientID is an OAuth2 client identifier
+	ClientID string `yaml:"client_id"`
+	// ClientSecret is an OAuth2 client secret
+	ClientSecret string `yaml:"client_secret" secret:"true"`
+	// Scopes are requested access token scopes, can be omitted
+	Scopes []string `yaml:"scopes"`
+}
+
+// IsDayOff stores isdayoff.ru service settings
+type IsDayOff struct {
+	// URL is a service URL, https://isdayoff.ru by default
+	URL string `yaml:"url"`
+	// Timeout limits every service request attempt time. Default: 10s.
+	Timeout time.Duration `yaml:"timeout"`
+	// Retry stores service requests retry settings
+	Retry Retry `yaml:"retry"`
+}
+
+// Retry stores HTTP requests retry settings.
+// Zero values are replaced with defaults.
+type Retry struct {
+	// MaxAttempts is a maximum number of request attempts,
+	// 1 disables retries. Default: 3.
+	MaxAttempts int `yaml:"max_attempts"`
+	// InitialBackoff is a delay before the first retry,
+	// it doubles on every next retry. Default: 200ms.
+	InitialBackoff time.Duration `yaml:"initial_backoff"`
+	// MaxBackoff is a maximum delay between retries. Default: 10s.
+	MaxBackoff time.Duration `yaml:"max_backoff"`
+	// MaxRetryAfter is a maximum delay requested in Retry-After header
+	// to wait for, the response is returned if the delay is longer. Default: 1m.
+	MaxRetryAfter time.Duration `yaml:"max_retry_after"`
 }
 
 type Team struct {
@@ -36,8 +137,229 @@ type Team struct {
 	Name string `yaml:"name"`
 	// Channel stores identifier in mattermost for this team
 	Channel string `yaml:"channel"`
+	// Timezone is a team IANA time zone name, e.g. 'Europe/Moscow', UTC by default
+	Timezone string `yaml:"timezone"`
 	// Members is a list of members in team
 	Members []Member `yaml:"members"`
+	// Discovery defines where members are resolved at startup,
+	// they are added to the listed members
+	Discovery Discovery `yaml:"discovery"`
+	// Scope limits issues which work logs are counted
+	Scope Scope `yaml:"scope"`
+	// CommentRules are work log comment requirements
+	CommentRules CommentRules `yaml:"comment_rules"`
+	// Notify defines when the daily report is sent
+	Notify NotifyPolicy `yaml:"notify"`
+	// Lead is a team lead Mattermost username, the members manager
+	// if it is not set for the member
+	Lead string `yaml:"lead"`
+	// Escalation defines reminders of members missing time logs several days in a row
+	Escalation Escalation `yaml:"escalation"`
+}
+
+// Default escalation levels
+const (
+	defaultEscalationChannelFrom = 2
+	defaultEscalationManagerFrom = 3
+)
+
+// Escalation defines reminders by consecutive missed working days.
+// The member gets a direct message first, is tagged in the team channel
+// from ChannelFrom day and the manager is notified from ManagerFrom day.
+type Escalation struct {
+	// Enabled turns escalations on, it requires state.path
+	Enabled bool `yaml:"enabled"`
+	// ChannelFrom is a missed day the member is tagged in the channel from. Default: 2.
+	ChannelFrom int `yaml:"channel_from"`
+	// ManagerFrom is a missed day the manager is notified from. Default: 3.
+	ManagerFrom int `yaml:"manager_from"`
+}
+
+// ChannelDay returns the missed day the member is tagged in the channel from
+func (e Escalation) ChannelDay() int {
+	if e.ChannelFrom > 0 {
+		return e.ChannelFrom
+	}
+
+	return defaultEscalationChannelFrom
+}
+
+// ManagerDay returns the missed day the manager is notified from
+func (e Escalation) ManagerDay() int {
+	if e.ManagerFrom > 0 {
+		return e.ManagerFrom
+	}
+
+	return defaultEscalationManagerFrom
+}
+
+// Location returns the team time zone, UTC if it is not set or unknown
+func (t Team) Location() *time.Location {
+	loc, err := time.LoadLocation(t.Timezone)
+	if err != nil {
+		return time.UTC
+	}
+
+	return loc
+}
+
+// Daily report notification policies
+const (
+	// NotifyAlways sends the report every working day
+	NotifyAlways = "always"
+	// NotifyOnlyWhenMissing sends the report only if someone has not logged time
+	NotifyOnlyWhenMissing = "only-when-missing"
+	// NotifyFridaySummary sends the week stats on Fridays instead of daily reports
+	NotifyFridaySummary = "friday-summary"
+)
+
+// NotifyPolicy defines when the team daily report is sent
+type NotifyPolicy struct {
+	// Policy is one of NotifyAlways (default),
+	// NotifyOnlyWhenMissing or NotifyFridaySummary
+	Policy string `yaml:"policy"`
+	// QuietHours is a time range when nothing is sent to the team
+	QuietHours QuietHours `yaml:"quiet_hours"`
+	// FollowUp is one of FollowUpPost (default), FollowUpEdit or FollowUpThread,
+	// it defines how repeated reports for the same day are sent
+	FollowUp string `yaml:"follow_up"`
+}
+
+// Repeated daily report follow-ups
+const (
+	// FollowUpPost sends every report as a new post
+	FollowUpPost = "post"
+	// FollowUpEdit updates the first report post of the day
+	FollowUpEdit = "edit"
+	// FollowUpThread replies in the thread of the first report post of the day
+	FollowUpThread = "thread"
+)
+
+// FollowsUp reports whether repeated reports follow up the first one of the day
+func (n NotifyPolicy) FollowsUp() bool {
+	return n.FollowUp == FollowUpEdit || n.FollowUp == FollowUpThread
+}
+
+// quietHoursFormat is a format of quiet hours bounds
+const quietHoursFormat = "15:04"
+
+// QuietHours is a daily time range in the team time zone,
+// e.g. from '22:00' to '09:00'. The range can span midnight.
+type QuietHours struct {
+	From string `yaml:"from"`
+	To   string `yaml:"to"`
+}
+
+// Contains reports whether the time is within quiet hours.
+// Unset or malformed range contains nothing.
+func (q QuietHours) Contains(t time.Time) bool {
+	from, err := time.Parse(quietHoursFormat, q.From)
+	if err != nil {
+		return false
+	}
+	to, err := time.Parse(quietHoursFormat, q.To)
+	if err != nil {
+		return false
+	}
+
+	minutes := func(t time.Time) int { return t.Hour()*60 + t.Minute() }
+	m, f, e := minutes(t), minutes(from), minutes(to)
+	if f <= e {
+		return m >= f && m < e
+	}
+
+	return m >= f || m < e
+}
+
+// CommentRules are work log comment requirements.
+// Violations are listed in the daily report, zero rules are not checked.
+type CommentRules struct {
+	// Required reports work logs without comment
+	Required bool `yaml:"required"`
+	// MinLength is a minimum comment length in characters
+	MinLength int `yaml:"min_length"`
+	// Forbidden are placeholder comments, e.g. 'work', compared ignoring case
+	Forbidden []string `yaml:"forbidden"`
+	// Pattern is a regular expression the comment must contain,
+	// e.g. issue reference '[A-Z]+-[0-9]+'
+	Pattern string `yaml:"pattern"`
+}
+
+// Enabled reports whether any rule is set
+func (r CommentRules) Enabled() bool {
+	return r.Required || r.MinLength > 0 || len(r.Forbidden) > 0 || r.Pattern != ""
+}
+
+// Scope limits issues which work logs are counted as team work.
+// Time logged to other issues is reported as out of scope.
+type Scope struct {
+	// Projects are project keys, all projects by default
+	Projects []string `yaml:"projects"`
+	// ExcludeIssueTypes are issue types not counted, e.g. 'Epic'
+	ExcludeIssueTypes []string `yaml:"exclude_issue_types"`
+	// ExcludeLabels are labels of issues not counted
+	ExcludeLabels []string `yaml:"exclude_labels"`
+	// JQL is an additional condition, e.g. 'component = Backend',
+	// it can not contain ORDER BY clause
+	JQL string `yaml:"jql"`
+}
+
+// Condition returns the scope JQL condition or empty string if scope is not set
+func (s Scope) Condition() string {
+	var conds []string
+	if len(s.Projects) > 0 {
+		conds = append(conds, "project in ("+jqlList(s.Projects)+")")
+	}
+	if len(s.ExcludeIssueTypes) > 0 {
+		conds = append(conds, "issuetype not in ("+jqlList(s.ExcludeIssueTypes)+")")
+	}
+	if len(s.ExcludeLabels) > 0 {
+		// 'not in' does not match issues without labels
+		conds = append(conds, "(labels is EMPTY OR labels not in ("+jqlList(s.ExcludeLabels)+"))")
+	}
+	if s.JQL != "" {
+		conds = append(conds, "("+s.JQL+")")
+	}
+
+	return strings.Join(conds, " AND ")
+}
+
+// jqlList returns comma separated quoted JQL values
+func jqlList(values []string) string {
+	quoted := make([]string, 0, len(values))
+	for _, v := range values {
+		quoted = append(quoted, jqlQuote(v))
+	}
+
+	return strings.Join(quoted, ", ")
+}
+
+// jqlEscaper escapes JQL string literal special characters
+var jqlEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`, "\r", `\r`, "\t", `\t`)
+
+// jqlQuote returns the value as a double-quoted JQL string literal
+func jqlQuote(v string) string {
+	return `"` + jqlEscaper.Replace(v) + `"`
+}
+
+// Discovery defines sources of team members. Jira users are matched
+// to Mattermost users by email. If only Mattermost channel is set,
+// Jira accounts are searched by channel members emails.
+type Discovery struct {
+	// JiraGroup is a Jira group which users are team members
+	JiraGroup string `yaml:"jira_group"`
+	// JiraProject is a Jira project key which JiraRole users are team members
+	JiraProject string `yaml:"jira_project"`
+	// JiraRole is a Jira project role name, e.g. 'Developers'
+	JiraRole string `yaml:"jira_role"`
+	// MattermostChannel is a Mattermost channel identifier
+	// which users are team members
+	MattermostChannel string `yaml:"mattermost_channel"`
+}
+
+// Enabled reports whether any members source is set
+func (d Discovery) Enabled() bool {
+	return d.JiraGroup != "" || d.JiraProject != "" || d.MattermostChannel != ""
 }
 
 type Member struct {
@@ -51,6 +373,19 @@ type Member struct {
 	MattermostUsername string `yaml:"mattermost_username"`
 	// Email is a user email, can be omitted
 	Email string `yaml:"email"`
+	// Manager is a member manager Mattermost username,
+	// the team lead is used if it is empty
+	Manager string `yaml:"manager"`
+}
+
+// Mention returns the member Mattermost mention, members without
+// Mattermost username, e.g. discovered in Jira, are named
+func (m Member) Mention() string {
+	if m.MattermostUsername == "" {
+		return m.Name
+	}
+
+	return "@" + m.MattermostUsername
 }
 
 // Teams stores list of teams and members of this teams
@@ -59,6 +394,17 @@ type Teams []Team
 // Notifier stores notifiers (Mattermost) settings
 type Notifier struct {
 	Mattermost `yaml:"mattermost"`
+	// Admin is a channel of the tool administrators
+	Admin Admin `yaml:"admin"`
+}
+
+// Admin stores administrators notification settings
+type Admin struct {
+	// Channel receives failure alerts with error chains,
+	// nothing is sent if it is empty
+	Channel string `yaml:"channel"`
+	// Summary sends run summary to the channel after every run
+	Summary bool `yaml:"summary"`
 }
 
 // Mattermost stores Mattermost server URL and authentication token
@@ -67,21 +413,132 @@ type Mattermost struct {
 	URL string `yaml:"url"`
 	// AuthToken ia a Mattermost authentication token
 	// https://docs.mattermost.com/integrations/cloud-personal-access-tokens.html#:~:text=Sign%20in%20to%20the%20user,Select%20Save.
-	AuthToken string `yaml:"auth_token"`
+	AuthToken string `yaml:"auth_token" secret:"true"`
+	// Timeout limits every Mattermost request attempt time. Default: 3s.
+	Timeout time.Duration `yaml:"timeout"`
+	// Retry stores Mattermost requests retry settings
+	Retry Retry `yaml:"retry"`
+	// Attachments sends reports as message attachments colored by member remaining time
+	Attachments bool `yaml:"attachments"`
+}
+
+// Server stores HTTP API server settings for the 'http' command
+type Server struct {
+	// Listen is a TCP address to listen on, ':8080' by default
+	Listen string `yaml:"listen"`
+	// AuthToken is a token which API clients must send
+	// in 'Authorization: Bearer <token>' header
+	AuthToken string `yaml:"auth_token" secret:"true"`
+	// PublicURL is the API URL reachable from Mattermost, it enables
+	// the report 'Recheck' button, it requires Mattermost attachments
+	PublicURL string `yaml:"public_url"`
+}
+
+// RecheckPath is the API path called back by the report 'Recheck' button
+const RecheckPath = "/actions/recheck"
+
+// RecheckURL returns the report 'Recheck' button URL,
+// it is empty if PublicURL is not set
+func (s Server) RecheckURL() string {
+	if s.PublicURL == "" {
+		return ""
+	}
+
+	return strings.TrimSuffix(s.PublicURL, "/") + RecheckPath
+}
+
+// Sign returns the signature of the values made with the auth token.
+// It authorizes callbacks unable to send the token, e.g. message buttons.
+func (s Server) Sign(values ...string) string {
+	mac := hmac.New(sha256.New, []byte(s.AuthToken))
+	mac.Write([]byte(strings.Join(values, "\n")))
+
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// RecheckSignature returns the signature of the team report 'Recheck' button
+// of the date, the button is valid until the expires Unix time
+func (s Server) RecheckSignature(team Team, date, expires string) string {
+	return s.Sign(team.Name, team.Channel, date, expires)
+}
+
+// Log stores logger settings
+type Log struct {
+	// Level is a minimal level of logged records: debug, info, warn or error.
+	// Default: info.
+	Level string `yaml:"level"`
+	// Format is a log records format: text or json. Default: text.
+	Format string `yaml:"format"`
 }
 
 // Args command-line parameters
 type Args struct {
+	// Command is a command to run, CmdNotify by default
+	Command string
 	// ConfigPath is a path to config file 'config.yml'
 	ConfigPath string
 	// Date is a day for which time spends will be checked
 	Date time.Time
+	// From is a first day of the period for the 'stats', 'late' and 'me' commands
+	From time.Time
+	// To is a last day of the period for the 'stats', 'late' and 'me' commands
+	To time.Time
+	// Notify makes the 'stats' and 'late' commands post their reports
+	// to team channels
+	Notify bool
+	// LateDays is a number of days after the work start when
+	// the work log is treated as written retroactively
+	LateDays int
+	// Write makes the 'resolve-members' command update the config file
+	// instead of printing suggestions
+	Write bool
+	// Timeout limits the whole command run time, zero means no limit.
+	// It is not applied to the 'http' command.
+	Timeout time.Duration
+	// Detailed adds logged time breakdown by project, epic and issue
+	// to the daily report
+	Detailed bool
+	// DryRun prints messages with their target notifier and channel
+	// instead of sending them
+	DryRun bool
+	// Teams are names of the teams to check, all teams by default
+	Teams []string
+	// User is a Jira account ID or email of the 'me' command user
+	User string
+	// Members are selectors (name, Jira account ID, Mattermost username
+	// or email) of the members to check, all members by default.
+	// Reports of selected members are printed instead of sending.
+	Members []string
 }
 
-// ProcessArgs processes command arguments and fills the Args structure
+// listFlag returns flag parser appending comma separated values to the list
+func listFlag(list *[]string) func(string) error {
+	return func(s string) error {
+		for _, v := range strings.Split(s, ",") {
+			if v = strings.TrimSpace(v); v != "" {
+				*list = append(*list, v)
+			}
+		}
+
+		return nil
+	}
+}
+
+// ProcessArgs processes command arguments and fills the Args structure.
+// The first argument can be a command name, e.g. 'stats'.
 func ProcessArgs(args []string) (Args, error) {
 	var a Args
 
+	if len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		a.Command = args[0]
+		args = args[1:]
+	}
+	switch a.Command {
+	case CmdNotify, CmdStats, CmdLate, CmdHTTP, CmdMe, CmdValidate, CmdResolveMembers:
+	default:
+		return Args{}, fmt.Errorf("%w: '%s'", ErrUnknownCommand, a.Command)
+	}
+
 	f := flag.NewFlagSet("time spends notifier", 1)
 	f.StringVar(
 		&a.ConfigPath,
@@ -90,7 +547,6 @@ func ProcessArgs(args []string) (Args, error) {
 		"Path to configuration file",
 	)
 
-	const dayFormat = "2006-01-02"
 	var date string
 	f.StringVar(
 		&date,
@@ -99,6 +555,75 @@ func ProcessArgs(args []string) (Args, error) {
 		"What day is to be reported, format: "+dayFormat+".",
 	)
 
+	var from, to string
+	f.StringVar(
+		&from,
+		"from",
+		"",
+		"First day of the 'stats', 'late' and 'me' period, format: "+dayFormat+". "+
+			"Default: first day of the month of the reported day.",
+	)
+	f.StringVar(
+		&to,
+		"to",
+		"",
+		"Last day of the 'stats', 'late' and 'me' period, format: "+dayFormat+". "+
+			"Default: reported day.",
+	)
+	f.BoolVar(
+		&a.Notify,
+		"notify",
+		false,
+		"Post the 'stats' and 'late' reports to team channels instead of stdout.",
+	)
+	f.IntVar(
+		&a.LateDays,
+		"late-days",
+		1,
+		"Days after the work start when the 'late' command treats its log as retroactive.",
+	)
+	f.BoolVar(
+		&a.Write,
+		"write",
+		false,
+		"Write found member identifiers to the config file in the 'resolve-members' command.",
+	)
+	f.DurationVar(
+		&a.Timeout,
+		"timeout",
+		0,
+		"Time limit of the whole run, e.g. '2m'. Zero means no limit.",
+	)
+	f.BoolVar(
+		&a.Detailed,
+		"detailed",
+		false,
+		"Add logged time breakdown by project, epic and issue to the daily report.",
+	)
+	f.BoolVar(
+		&a.DryRun,
+		"dry-run",
+		false,
+		"Print messages with their target notifier and channel instead of sending them.",
+	)
+	f.StringVar(
+		&a.User,
+		"user",
+		"",
+		"Jira account ID or email of the user checked by the 'me' command.",
+	)
+	f.Func(
+		"team",
+		"Comma separated names of the teams to check. Default: all teams.",
+		listFlag(&a.Teams),
+	)
+	f.Func(
+		"member",
+		"Comma separated members to check by name, Jira account ID, Mattermost username or email. "+
+			"Reports are printed to stdout instead of sending. Default: all members.",
+		listFlag(&a.Members),
+	)
+
 	if err := f.Parse(args); err != nil {
 		_, _ = fmt.Fprintln(f.Output())
 		return Args{}, err
@@ -110,25 +635,99 @@ func ProcessArgs(args []string) (Args, error) {
 	}
 	a.Date = t
 
+	switch {
+	case a.Command == CmdStats || a.Command == CmdLate:
+		if a.From, a.To, err = period(a.Date, from, to); err != nil {
+			return Args{}, err
+		}
+	case a.Command == CmdHTTP && len(a.Members) > 0:
+		// the service reports and notifies whole teams
+		return Args{}, fmt.Errorf("%w: '%s'", ErrMemberNotAllowed, a.Command)
+	case a.Command == CmdMe:
+		if a.User == "" {
+			return Args{}, ErrNoUser
+		}
+		// the reported day is checked if no period is given
+		a.From, a.To = a.Date, a.Date
+		if from != "" || to != "" {
+			if a.From, a.To, err = period(a.Date, from, to); err != nil {
+				return Args{}, err
+			}
+		}
+	}
+
 	return a, nil
 }
 
-// ReadConfig reads config file and fills Params structure
-func ReadConfig(path string) (Params, error) {
-	f, err := os.Open(path)
+// period parses period bounds. Omitted start defaults to the first
+// day of the day's month, omitted end defaults to the day itself.
+func period(day time.Time, from, to string) (start, end time.Time, err error) {
+	start = time.Date(day.Year(), day.Month(), 1, 0, 0, 0, 0, time.UTC)
+	if from != "" {
+		if start, err = time.Parse(dayFormat, from); err != nil {
+			return time.Time{}, time.Time{}, ErrBadDayFormat
+		}
+	}
+
+	end = day
+	if to != "" {
+		if end, err = time.Parse(dayFormat, to); err != nil {
+			return time.Time{}, time.Time{}, ErrBadDayFormat
+		}
+	}
+
+	if start.After(end) {
+		return time.Time{}, time.Time{}, ErrBadPeriod
+	}
+
+	return start, end, nil
+}
+
+// ReadConfig reads config file and fills Params structure.
+// String values can reference environment variables: '${ENV_VAR}' is replaced
+// with the variable value. Tokens and passwords can also reference secrets:
+// 'file:/path' is replaced with the file content and '<scheme>:<ref>'
+// with the secret of the provider with the scheme.
+// Unknown fields and invalid values are reported all at once
+// with ValidationError.
+func ReadConfig(ctx context.Context, path string, providers ...SecretsProvider) (Params, error) {
+	cfgBytes, err := os.ReadFile(path)
 	if err != nil {
 		return Params{}, err
 	}
 
-	cfgBytes, err := io.ReadAll(f)
+	params, v, err := parse(ctx, cfgBytes, providers)
 	if err != nil {
-		return Params{}, fmt.Errorf("reading config file '%s': %w", path, err)
+		return Params{}, err
+	}
+	v.validate(params)
+	if err = v.err(); err != nil {
+		return Params{}, err
+	}
+
+	return params, nil
+}
+
+// parse decodes config data with secrets resolved. Unknown fields and
+// bad values problems are collected by the returned validator.
+func parse(ctx context.Context, data []byte, providers []SecretsProvider) (Params, *validator, error) {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return Params{}, nil, fmt.Errorf("unmarshal config file data: %w", err)
+	}
+
+	if err := newSecretsResolver(providers).resolveNode(ctx, &doc, reflect.TypeOf(Params{}), false); err != nil {
+		return Params{}, nil, fmt.Errorf("resolving config secrets: %w", err)
 	}
 
 	var params Params
-	if err = yaml.Unmarshal(cfgBytes, &params); err != nil {
-		return Params{}, fmt.Errorf("unmarshal config file data: %w", err)
+	v := &validator{lines: fieldLines{}}
+	v.checkFields(&doc, reflect.TypeOf(params), "")
+	if doc.Kind != 0 {
+		if err := doc.Decode(&params); err != nil && !v.decodeErrors(err) {
+			return Params{}, nil, fmt.Errorf("unmarshal config file data: %w", err)
+		}
 	}
 
-	return params, nil
+	return params, v, nil
 }
@@ -1,6 +1,7 @@
 package config
 
 import (
+	"context"
 	"github.com/stretchr/testify/require"
 	"os"
 	"reflect"
@@ -12,16 +13,41 @@ func TestReadConfig(t *testing.T) {
 	want := Params{
 		Jira: Jira{
 			URL:       "https://myorg.atlassian.net",
+			AuthMode:  JiraAuthBasic,
 			UserEmail: "user@emample.com",
 			AuthToken: "<user-token>",
+			EpicField: "customfield_10014",
+			Timeout:   30 * time.Second,
+			Retry: Retry{
+				MaxAttempts:    3,
+				InitialBackoff: 200 * time.Millisecond,
+				MaxBackoff:     10 * time.Second,
+				MaxRetryAfter:  time.Minute,
+			},
+		},
+		IsDayOff: IsDayOff{
+			URL: "https://isdayoff.ru",
+		},
+		Notifier: Notifier{
+			Mattermost: Mattermost{
+				URL:         "https://chat.myorg.com",
+				AuthToken:   "<service-user-token>",
+				Attachments: true,
+			},
+			Admin: Admin{Channel: "<admin-channel-ID>", Summary: true},
+		},
+		Log: Log{
+			Level:  "info",
+			Format: "text",
+		},
+		Server: Server{
+			Listen:    ":8080",
+			AuthToken: "<api-clients-token>",
 		},
-		Notifier: Notifier{Mattermost{
-			URL:       "https://chat.myorg.com",
-			AuthToken: "<service-user-token>",
-		}},
 		Teams: Teams{Team{
-			Name:    "my-jira-team-name",
-			Channel: "<my-mattermost-team-channel-ID>",
+			Name:     "my-jira-team-name",
+			Channel:  "<my-mattermost-team-channel-ID>",
+			Timezone: "Europe/Moscow",
 			Members: []Member{{
 				Name:               "<my team member 1>",
 				JiraAccID:          "<team member 1 jira account ID>",
@@ -37,7 +63,7 @@ func TestReadConfig(t *testing.T) {
 	}
 
 	path := "config-example.yml"
-	got, err := ReadConfig(path)
+	got, err := ReadConfig(context.Background(), path)
 	if err != nil {
 		t.Errorf("ReadConfig() error = %v, wantErr: nil", err)
 		return
@@ -50,57 +76,284 @@ func TestReadConfig(t *testing.T) {
 
 func TestReadConfigFileNotExist(t *testing.T) {
 	path := "config-file-not-exists.yml"
-	params, err := ReadConfig(path)
+	params, err := ReadConfig(context.Background(), path)
 	require.Equal(t, true, os.IsNotExist(err))
 	require.Equal(t, Jira{}, params.Jira)
 	require.Equal(t, Mattermost{}, params.Mattermost)
 }
 
+// argsTest is a ProcessArgs test case
+type argsTest struct {
+	name    string
+	args    []string
+	want    Args
+	wantErr error
+}
+
+func testProcessArgs(t *testing.T, tests []argsTest) {
+	t.Helper()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ProcessArgs(tt.args)
+			if tt.wantErr != nil {
+				require.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.want, got)
+		})
+	}
+}
+
 func TestProcessArgs(t *testing.T) {
-	tests := []struct {
-		name    string
-		args    []string
-		want    Args
-		wantErr bool
-	}{
+	testProcessArgs(t, []argsTest{
 		{
 			name: "no args",
 			args: []string{},
 			want: Args{
 				ConfigPath: "./config.yml",
+				LateDays:   1,
 				Date:       time.Now().UTC().Truncate(24 * time.Hour),
 			},
-			wantErr: false,
 		},
 		{
 			name: "set custom date",
 			args: []string{"-d=2023-09-09"},
 			want: Args{
 				ConfigPath: "./config.yml",
+				LateDays:   1,
+				Date:       time.Date(2023, 9, 9, 0, 0, 0, 0, time.UTC),
+			},
+		},
+		{
+			name: "set timeout",
+			args: []string{"-d=2023-09-09", "-timeout=2m"},
+			want: Args{
+				ConfigPath: "./config.yml",
+				LateDays:   1,
+				Date:       time.Date(2023, 9, 9, 0, 0, 0, 0, time.UTC),
+				Timeout:    2 * time.Minute,
+			},
+		},
+		{
+			name: "dry run with detailed report",
+			args: []string{"-d=2023-09-09", "-dry-run", "-detailed"},
+			want: Args{
+				ConfigPath: "./config.yml",
+				LateDays:   1,
+				Date:       time.Date(2023, 9, 9, 0, 0, 0, 0, time.UTC),
+				Detailed:   true,
+				DryRun:     true,
+			},
+		},
+		{
+			name: "select teams and members",
+			args: []string{"-d=2023-09-09", "-team=backend, mobile", "-member=ivanov.i", "-member=acc2"},
+			want: Args{
+				ConfigPath: "./config.yml",
+				LateDays:   1,
 				Date:       time.Date(2023, 9, 9, 0, 0, 0, 0, time.UTC),
+				Teams:      []string{"backend", "mobile"},
+				Members:    []string{"ivanov.i", "acc2"},
 			},
-			wantErr: false,
 		},
 		{
 			name: "set custom config",
 			args: []string{"-c=custom-config.yml"},
 			want: Args{
 				ConfigPath: "custom-config.yml",
+				LateDays:   1,
 				Date:       time.Now().Truncate(24 * time.Hour).UTC(),
 			},
-			wantErr: false,
+		},
+		{
+			name:    "bad date",
+			args:    []string{"-d=09.09.2023"},
+			wantErr: ErrBadDayFormat,
+		},
+		{
+			name:    "unknown command",
+			args:    []string{"statz"},
+			wantErr: ErrUnknownCommand,
+		},
+	})
+}
+
+func TestProcessArgsStats(t *testing.T) {
+	testProcessArgs(t, []argsTest{
+		{
+			name: "default period",
+			args: []string{"stats", "-d=2023-09-09"},
+			want: Args{
+				Command:    CmdStats,
+				ConfigPath: "./config.yml",
+				LateDays:   1,
+				Date:       time.Date(2023, 9, 9, 0, 0, 0, 0, time.UTC),
+				From:       time.Date(2023, 9, 1, 0, 0, 0, 0, time.UTC),
+				To:         time.Date(2023, 9, 9, 0, 0, 0, 0, time.UTC),
+			},
+		},
+		{
+			name: "custom period",
+			args: []string{"stats", "-from=2023-08-01", "-to=2023-08-31", "-notify"},
+			want: Args{
+				Command:    CmdStats,
+				ConfigPath: "./config.yml",
+				LateDays:   1,
+				Date:       time.Now().UTC().Truncate(24 * time.Hour),
+				From:       time.Date(2023, 8, 1, 0, 0, 0, 0, time.UTC),
+				To:         time.Date(2023, 8, 31, 0, 0, 0, 0, time.UTC),
+				Notify:     true,
+			},
+		},
+		{
+			name:    "bad period",
+			args:    []string{"stats", "-from=2023-09-02", "-to=2023-09-01"},
+			wantErr: ErrBadPeriod,
+		},
+		{
+			name:    "bad period day",
+			args:    []string{"stats", "-from=2023.09.02"},
+			wantErr: ErrBadDayFormat,
+		},
+	})
+}
+
+func TestProcessArgsLate(t *testing.T) {
+	testProcessArgs(t, []argsTest{
+		{
+			name: "custom period",
+			args: []string{"late", "-from=2023-08-01", "-to=2023-08-31", "-late-days=3"},
+			want: Args{
+				Command:    CmdLate,
+				ConfigPath: "./config.yml",
+				Date:       time.Now().UTC().Truncate(24 * time.Hour),
+				From:       time.Date(2023, 8, 1, 0, 0, 0, 0, time.UTC),
+				To:         time.Date(2023, 8, 31, 0, 0, 0, 0, time.UTC),
+				LateDays:   3,
+			},
+		},
+		{
+			name:    "bad period",
+			args:    []string{"late", "-from=2023-09-02", "-to=2023-09-01"},
+			wantErr: ErrBadPeriod,
+		},
+	})
+}
+
+func TestProcessArgsMe(t *testing.T) {
+	testProcessArgs(t, []argsTest{
+		{
+			name: "day",
+			args: []string{"me", "-d=2023-09-09", "-user=ivanov@myorg.com"},
+			want: Args{
+				Command:    CmdMe,
+				ConfigPath: "./config.yml",
+				LateDays:   1,
+				Date:       time.Date(2023, 9, 9, 0, 0, 0, 0, time.UTC),
+				From:       time.Date(2023, 9, 9, 0, 0, 0, 0, time.UTC),
+				To:         time.Date(2023, 9, 9, 0, 0, 0, 0, time.UTC),
+				User:       "ivanov@myorg.com",
+			},
+		},
+		{
+			name: "period",
+			args: []string{"me", "-d=2023-09-09", "-from=2023-09-04", "-user=acc1"},
+			want: Args{
+				Command:    CmdMe,
+				ConfigPath: "./config.yml",
+				LateDays:   1,
+				Date:       time.Date(2023, 9, 9, 0, 0, 0, 0, time.UTC),
+				From:       time.Date(2023, 9, 4, 0, 0, 0, 0, time.UTC),
+				To:         time.Date(2023, 9, 9, 0, 0, 0, 0, time.UTC),
+				User:       "acc1",
+			},
+		},
+		{
+			name:    "without user",
+			args:    []string{"me"},
+			wantErr: ErrNoUser,
+		},
+	})
+}
+
+func TestProcessArgsHTTP(t *testing.T) {
+	testProcessArgs(t, []argsTest{
+		{
+			name: "select teams",
+			args: []string{"http", "-d=2023-09-09", "-team=backend"},
+			want: Args{
+				Command:    CmdHTTP,
+				ConfigPath: "./config.yml",
+				LateDays:   1,
+				Date:       time.Date(2023, 9, 9, 0, 0, 0, 0, time.UTC),
+				Teams:      []string{"backend"},
+			},
+		},
+		{
+			name:    "select members",
+			args:    []string{"http", "-member=ivanov.i"},
+			wantErr: ErrMemberNotAllowed,
+		},
+	})
+}
+
+func TestScope_Condition(t *testing.T) {
+	tests := []struct {
+		name  string
+		scope Scope
+		want  string
+	}{
+		{"empty", Scope{}, ""},
+		{"projects", Scope{Projects: []string{"PRJ", "OPS"}}, `project in ("PRJ", "OPS")`},
+		{"escaped", Scope{ExcludeLabels: []string{`a"b\c`}}, `(labels is EMPTY OR labels not in ("a\"b\\c"))`},
+		{
+			name: "all conditions",
+			scope: Scope{
+				Projects:          []string{"PRJ"},
+				ExcludeIssueTypes: []string{"Epic"},
+				ExcludeLabels:     []string{"no-track"},
+				JQL:               "component = Backend",
+			},
+			want: `project in ("PRJ") AND issuetype not in ("Epic") AND ` +
+				`(labels is EMPTY OR labels not in ("no-track")) AND (component = Backend)`,
 		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			got, err := ProcessArgs(tt.args)
-			if (err != nil) != tt.wantErr {
-				t.Errorf("ProcessArgs() error = %v, wantErr %v", err, tt.wantErr)
-				return
-			}
-			if !reflect.DeepEqual(got, tt.want) {
-				t.Errorf("ProcessArgs() got = %v, want %v", got, tt.want)
-			}
+			require.Equal(t, tt.want, tt.scope.Condition())
 		})
 	}
 }
+
+func TestQuietHours_Contains(t *testing.T) {
+	at := func(hour, minute int) time.Time {
+		return time.Date(2023, 9, 1, hour, minute, 0, 0, time.UTC)
+	}
+	tests := []struct {
+		name string
+		qh   QuietHours
+		t    time.Time
+		want bool
+	}{
+		{"not set", QuietHours{}, at(3, 0), false},
+		{"within day range", QuietHours{From: "12:00", To: "14:00"}, at(13, 59), true},
+		{"range end", QuietHours{From: "12:00", To: "14:00"}, at(14, 0), false},
+		{"after midnight", QuietHours{From: "22:00", To: "09:00"}, at(2, 30), true},
+		{"before midnight", QuietHours{From: "22:00", To: "09:00"}, at(22, 0), true},
+		{"out of night range", QuietHours{From: "22:00", To: "09:00"}, at(9, 0), false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.Equal(t, tt.want, tt.qh.Contains(tt.t))
+		})
+	}
+}
+
+func TestServer_Sign(t *testing.T) {
+	s := Server{AuthToken: "secret"}
+
+	require.Equal(t, s.Sign("team1", "2023-08-31"), s.Sign("team1", "2023-08-31"))
+	require.NotEqual(t, s.Sign("team1", "2023-08-31"), s.Sign("team1", "2023-09-01"))
+	require.NotEqual(t, s.Sign("team1", "2023-08-31"), Server{AuthToken: "other"}.Sign("team1", "2023-08-31"))
+}
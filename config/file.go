@@ -0,0 +1,114 @@
+package config
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+
+	"gopkg.in/yaml.v3"
+)
+
+// yamlIndent is an indentation of the written config
+const yamlIndent = 2
+
+var ErrNoMember = errors.New("no such team member in config")
+
+// File is a config file which members can be updated
+// keeping comments and secret references
+type File struct {
+	// Params are config parameters with secrets resolved.
+	// Team members are not validated.
+	Params Params
+	doc    yaml.Node
+}
+
+// ReadFile reads config file like ReadConfig, but does not
+// validate config semantics, e.g. to fill missing member identifiers
+func ReadFile(ctx context.Context, path string, providers ...SecretsProvider) (*File, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	params, v, err := parse(ctx, data, providers)
+	if err != nil {
+		return nil, err
+	}
+	if err := v.err(); err != nil {
+		return nil, err
+	}
+
+	f := &File{Params: params}
+	if err := yaml.Unmarshal(data, &f.doc); err != nil {
+		return nil, fmt.Errorf("unmarshal config file data: %w", err)
+	}
+
+	return f, nil
+}
+
+// SetMemberField sets the yaml field of the team member,
+// e.g. 'jira_account_id', the field is added if it is missing
+func (f *File) SetMemberField(team, member int, field, value string) error {
+	m := mappingValue(f.root(), "teams")
+	if m == nil || m.Kind != yaml.SequenceNode || team >= len(m.Content) {
+		return fmt.Errorf("%w: teams[%d]", ErrNoMember, team)
+	}
+	m = mappingValue(m.Content[team], "members")
+	if m == nil || m.Kind != yaml.SequenceNode || member >= len(m.Content) {
+		return fmt.Errorf("%w: teams[%d].members[%d]", ErrNoMember, team, member)
+	}
+	m = m.Content[member]
+
+	if v := mappingValue(m, field); v != nil {
+		v.Style = 0
+		v.SetString(value)
+		return nil
+	}
+
+	k := &yaml.Node{}
+	k.SetString(field)
+	v := &yaml.Node{}
+	v.SetString(value)
+	m.Content = append(m.Content, k, v)
+
+	return nil
+}
+
+// Bytes returns the config file content with changes
+func (f *File) Bytes() ([]byte, error) {
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(yamlIndent)
+	if err := enc.Encode(&f.doc); err != nil {
+		return nil, fmt.Errorf("encoding config: %w", err)
+	}
+	if err := enc.Close(); err != nil {
+		return nil, fmt.Errorf("encoding config: %w", err)
+	}
+
+	return buf.Bytes(), nil
+}
+
+func (f *File) root() *yaml.Node {
+	if len(f.doc.Content) == 0 {
+		return nil
+	}
+
+	return f.doc.Content[0]
+}
+
+// mappingValue returns the value node of the mapping key or nil
+func mappingValue(m *yaml.Node, key string) *yaml.Node {
+	if m == nil || m.Kind != yaml.MappingNode {
+		return nil
+	}
+	for i := 0; i+1 < len(m.Content); i += 2 {
+		if m.Content[i].Value == key {
+			return m.Content[i+1]
+		}
+	}
+
+	return nil
+}
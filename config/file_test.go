@@ -0,0 +1,54 @@
+package config
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestFile_SetMemberField(t *testing.T) {
+	t.Setenv("TSN_JIRA_TOKEN", "secret")
+	path := writeFile(t, "config.yml", `# ts-notifier config
+jira:
+  url: https://myorg.atlassian.net
+  user_email: user@myorg.com
+  auth_token: ${TSN_JIRA_TOKEN}
+teams:
+  - name: backend
+    channel: ch1
+    members:
+      - name: Ivan Ivanov # team lead
+        email: ivanov@myorg.com
+      - name: Petr Petrov
+        jira_account_id: ""
+`)
+
+	f, err := ReadFile(context.Background(), path)
+	require.NoError(t, err)
+	require.Equal(t, "secret", f.Params.Jira.AuthToken)
+	require.Equal(t, "ivanov@myorg.com", f.Params.Teams[0].Members[0].Email)
+
+	require.NoError(t, f.SetMemberField(0, 0, "jira_account_id", "acc1"))
+	require.NoError(t, f.SetMemberField(0, 1, "jira_account_id", "acc2"))
+	require.ErrorIs(t, f.SetMemberField(0, 2, "jira_account_id", "acc3"), ErrNoMember)
+	require.ErrorIs(t, f.SetMemberField(1, 0, "jira_account_id", "acc3"), ErrNoMember)
+
+	got, err := f.Bytes()
+	require.NoError(t, err)
+	require.Equal(t, `# ts-notifier config
+jira:
+  url: https://myorg.atlassian.net
+  user_email: user@myorg.com
+  auth_token: ${TSN_JIRA_TOKEN}
+teams:
+  - name: backend
+    channel: ch1
+    members:
+      - name: Ivan Ivanov # team lead
+        email: ivanov@myorg.com
+        jira_account_id: acc1
+      - name: Petr Petrov
+        jira_account_id: acc2
+`, string(got))
+}
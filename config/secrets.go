@@ -0,0 +1,198 @@
+package config
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"reflect"
+	"regexp"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+var ErrSecretNotFound = errors.New("secret not found")
+
+// fileScheme is a prefix of references to secrets stored in files,
+// e.g. 'file:/run/secrets/jira_token'
+const fileScheme = "file"
+
+// envRef matches references to environment variables, e.g. '${JIRA_TOKEN}'
+var envRef = regexp.MustCompile(`\$\{([A-Za-z_][A-Za-z0-9_]*)\}`)
+
+// SecretsProvider resolves references to secrets kept in an external store.
+// A reference is a config value prefixed with the provider scheme and colon,
+// e.g. 'vault:secret/data/ts-notifier#jira_token'.
+type SecretsProvider interface {
+	// Scheme returns the references prefix without colon
+	Scheme() string
+	// Secret returns the secret value by the reference without scheme prefix
+	Secret(ctx context.Context, ref string) (string, error)
+}
+
+// secretsResolver replaces secret references in config values
+type secretsResolver struct {
+	providers map[string]SecretsProvider
+}
+
+func newSecretsResolver(providers []SecretsProvider) secretsResolver {
+	r := secretsResolver{providers: make(map[string]SecretsProvider, len(providers))}
+	for _, p := range providers {
+		r.providers[p.Scheme()] = p
+	}
+
+	return r
+}
+
+// resolveNode resolves environment variable references in scalar values
+// of the yaml tree decoded to the type t. File and provider references are
+// resolved in the fields tagged with 'secret:"true"' only.
+func (r secretsResolver) resolveNode(ctx context.Context, n *yaml.Node, t reflect.Type, secret bool) error {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	switch n.Kind {
+	case yaml.ScalarNode:
+		return r.resolveScalar(ctx, n, secret)
+	case yaml.DocumentNode:
+		for _, c := range n.Content {
+			if err := r.resolveNode(ctx, c, t, false); err != nil {
+				return err
+			}
+		}
+	case yaml.MappingNode:
+		var fields map[string]reflect.Type
+		var secrets map[string]bool
+		switch t.Kind() {
+		case reflect.Struct:
+			fields, secrets = yamlFields(t), secretFields(t)
+		case reflect.Map:
+		default:
+			return nil
+		}
+		// mapping keys are never resolved
+		for i := 0; i+1 < len(n.Content); i += 2 {
+			key, val := n.Content[i], n.Content[i+1]
+			ft, ok := fields[key.Value]
+			if t.Kind() == reflect.Map {
+				ft, ok = t.Elem(), true
+			}
+			// unknown fields are reported by the validator
+			if !ok {
+				continue
+			}
+			if err := r.resolveNode(ctx, val, ft, secrets[key.Value]); err != nil {
+				return err
+			}
+		}
+	case yaml.SequenceNode:
+		if t.Kind() != reflect.Slice {
+			return nil
+		}
+		for _, c := range n.Content {
+			if err := r.resolveNode(ctx, c, t.Elem(), false); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+func (r secretsResolver) resolveScalar(ctx context.Context, n *yaml.Node, secret bool) error {
+	if n.ShortTag() != "!!str" {
+		return nil
+	}
+	v, ref, err := r.resolve(ctx, n.Value, secret)
+	if err != nil {
+		return fmt.Errorf("line %d: %w", n.Line, err)
+	}
+	if v == n.Value {
+		return nil
+	}
+	n.Value = v
+	// the expanded plain value is typed by its content, e.g. 'max_attempts: ${ATTEMPTS}',
+	// but values like 'null' or '~' stay strings
+	if !ref && n.Style == 0 {
+		n.Tag = ""
+		if n.ShortTag() == "!!null" {
+			n.Tag = "!!str"
+		}
+	}
+
+	return nil
+}
+
+// resolve returns the value with secret references replaced.
+// File and provider references are resolved in secret values only,
+// ref reports whether the whole value is such a reference.
+func (r secretsResolver) resolve(ctx context.Context, value string, secret bool) (v string, ref bool, err error) {
+	if scheme, path, ok := strings.Cut(value, ":"); ok && secret {
+		if scheme == fileScheme {
+			v, err := readSecretFile(path)
+			return v, true, err
+		}
+		if p, ok := r.providers[scheme]; ok {
+			v, err := p.Secret(ctx, path)
+			if err != nil {
+				return "", true, fmt.Errorf("resolving '%s' secret: %w", value, err)
+			}
+			return v, true, nil
+		}
+	}
+
+	v = envRef.ReplaceAllStringFunc(value, func(ref string) string {
+		name := envRef.FindStringSubmatch(ref)[1]
+		ev, ok := os.LookupEnv(name)
+		if !ok && err == nil {
+			err = fmt.Errorf("%w: environment variable '%s' is not set", ErrSecretNotFound, name)
+		}
+		return ev
+	})
+	if err != nil {
+		return "", false, err
+	}
+
+	return v, false, nil
+}
+
+// secretFields returns whether struct fields keep secrets by their yaml names
+func secretFields(t reflect.Type) map[string]bool {
+	secrets := make(map[string]bool)
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if !f.IsExported() {
+			continue
+		}
+		name, opts, _ := strings.Cut(f.Tag.Get("yaml"), ",")
+		if opts == "inline" {
+			for n, s := range secretFields(f.Type) {
+				secrets[n] = s
+			}
+			continue
+		}
+		if name == "" {
+			name = strings.ToLower(f.Name)
+		}
+		if f.Tag.Get("secret") == "true" {
+			secrets[name] = true
+		}
+	}
+
+	return secrets
+}
+
+// readSecretFile returns the file content without trailing line breaks
+func readSecretFile(path string) (string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return "", fmt.Errorf("%w: %w", ErrSecretNotFound, err)
+		}
+		return "", fmt.Errorf("reading secret file: %w", err)
+	}
+
+	return strings.TrimRight(string(data), "\r\n"), nil
+}
@@ -0,0 +1,111 @@
+package config
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+type mapProvider map[string]string
+
+func (p mapProvider) Scheme() string { return "test" }
+
+func (p mapProvider) Secret(_ context.Context, ref string) (string, error) {
+	s, ok := p[ref]
+	if !ok {
+		return "", ErrSecretNotFound
+	}
+	return s, nil
+}
+
+func writeFile(t *testing.T, name, data string) string {
+	path := filepath.Join(t.TempDir(), name)
+	require.NoError(t, os.WriteFile(path, []byte(data), 0o600))
+
+	return path
+}
+
+func TestReadConfigSecrets(t *testing.T) {
+	t.Setenv("TSN_JIRA_HOST", "jira.example.com")
+	t.Setenv("TSN_ATTEMPTS", "5")
+	tokenPath := writeFile(t, "jira_token", "jira-secret\n")
+
+	path := writeFile(t, "config.yml", `
+jira:
+  url: https://${TSN_JIRA_HOST}
+  auth_mode: bearer
+  auth_token: file:`+tokenPath+`
+  retry:
+    max_attempts: ${TSN_ATTEMPTS}
+notifier:
+  mattermost:
+    auth_token: test:mattermost
+server:
+  auth_token: "$not-a-reference"
+`)
+
+	got, err := ReadConfig(context.Background(), path, mapProvider{"mattermost": "mm-secret"})
+	require.NoError(t, err)
+	require.Equal(t, Jira{
+		URL:       "https://jira.example.com",
+		AuthMode:  JiraAuthBearer,
+		AuthToken: "jira-secret",
+		Retry:     Retry{MaxAttempts: 5},
+	}, got.Jira)
+	require.Equal(t, "mm-secret", got.Mattermost.AuthToken)
+	require.Equal(t, "$not-a-reference", got.Server.AuthToken)
+}
+
+func TestParseSecretsOnlyInSecretFields(t *testing.T) {
+	t.Setenv("TSN_NULL", "null")
+	t.Setenv("TSN_TILDE", "~")
+
+	got, _, err := parse(context.Background(), []byte(`
+jira:
+  url: test:jira
+  user_email: ${TSN_NULL}
+  auth_token: ${TSN_TILDE}
+teams:
+  - name: file:/not/exist
+`), []SecretsProvider{mapProvider{"jira": "secret"}})
+	require.NoError(t, err)
+	require.Equal(t, "test:jira", got.Jira.URL)
+	require.Equal(t, "null", got.Jira.UserEmail)
+	require.Equal(t, "~", got.Jira.AuthToken)
+	require.Equal(t, "file:/not/exist", got.Teams[0].Name)
+}
+
+func TestReadConfigSecretsErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		config  string
+		wantErr string
+	}{
+		{
+			name:    "env not set",
+			config:  "jira:\n  auth_token: ${TSN_NOT_SET}\n",
+			wantErr: "line 2: secret not found: environment variable 'TSN_NOT_SET' is not set",
+		},
+		{
+			name:    "file not exist",
+			config:  "jira:\n  url: https://jira\n  auth_token: file:/not/exist\n",
+			wantErr: "line 3: secret not found",
+		},
+		{
+			name:    "provider secret not found",
+			config:  "server:\n  auth_token: test:server\n",
+			wantErr: "line 2: resolving 'test:server' secret: secret not found",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ReadConfig(context.Background(), writeFile(t, "config.yml", tt.config), mapProvider{})
+			require.True(t, errors.Is(err, ErrSecretNotFound))
+			require.ErrorContains(t, err, tt.wantErr)
+		})
+	}
+}
@@ -0,0 +1,109 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+var (
+	ErrUnknownTeam   = errors.New("unknown team")
+	ErrUnknownMember = errors.New("unknown member")
+)
+
+// Matches reports whether the member is identified by the selector:
+// name, Jira account ID, Mattermost username or email, ignoring case.
+func (m Member) Matches(selector string) bool {
+	for _, id := range []string{m.Name, m.JiraAccID, m.MattermostUsername, m.Email} {
+		if id != "" && strings.EqualFold(id, selector) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Select returns teams with the given names keeping only members
+// matched by the member selectors. Empty selectors select everything.
+// Teams without selected members are dropped. Every selector must match.
+func (ts Teams) Select(teams, members []string) (Teams, error) {
+	if len(teams) == 0 && len(members) == 0 {
+		return ts, nil
+	}
+
+	teamFound := make(map[string]bool, len(teams))
+	memberFound := make(map[string]bool, len(members))
+	var selected Teams
+	for _, t := range ts {
+		if len(teams) > 0 && !contains(teams, t.Name) {
+			continue
+		}
+		teamFound[t.Name] = true
+
+		if len(members) > 0 {
+			var found []string
+			t, found = t.SelectMembers(members)
+			for _, sel := range found {
+				memberFound[sel] = true
+			}
+			if len(t.Members) == 0 {
+				continue
+			}
+		}
+		selected = append(selected, t)
+	}
+
+	var errs []error
+	for _, name := range teams {
+		if !teamFound[name] {
+			errs = append(errs, fmt.Errorf("%w '%s'", ErrUnknownTeam, name))
+		}
+	}
+	for _, sel := range members {
+		if !memberFound[sel] {
+			errs = append(errs, fmt.Errorf("%w '%s'", ErrUnknownMember, sel))
+		}
+	}
+	if err := errors.Join(errs...); err != nil {
+		return nil, err
+	}
+
+	return selected, nil
+}
+
+// SelectMembers returns the team keeping only members matched by the
+// selectors and the selectors that matched. Empty selectors select everyone.
+func (t Team) SelectMembers(selectors []string) (Team, []string) {
+	if len(selectors) == 0 {
+		return t, nil
+	}
+
+	var (
+		members []Member
+		found   []string
+	)
+	for _, m := range t.Members {
+		for _, sel := range selectors {
+			if m.Matches(sel) {
+				if !contains(found, sel) {
+					found = append(found, sel)
+				}
+				members = append(members, m)
+				break
+			}
+		}
+	}
+	t.Members = members
+
+	return t, found
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+
+	return false
+}
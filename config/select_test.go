@@ -0,0 +1,68 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestTeams_Select(t *testing.T) {
+	ivanov := Member{Name: "Ivan Ivanov", JiraAccID: "acc1", MattermostUsername: "ivanov.i", Email: "ivanov@myorg.com"}
+	petrov := Member{Name: "Petr Petrov", JiraAccID: "acc2", MattermostUsername: "petrov.p"}
+	sidorov := Member{Name: "Sidor Sidorov", JiraAccID: "acc3"}
+	teams := Teams{
+		{Name: "backend", Members: []Member{ivanov, petrov}},
+		{Name: "mobile", Members: []Member{sidorov}},
+		{Name: "qa", Members: []Member{petrov}},
+	}
+
+	tests := []struct {
+		name    string
+		teams   []string
+		members []string
+		want    Teams
+		wantErr []error
+	}{
+		{name: "all", want: teams},
+		{
+			name:  "teams",
+			teams: []string{"backend", "qa"},
+			want:  Teams{teams[0], teams[2]},
+		},
+		{
+			name:    "member by email",
+			members: []string{"IVANOV@myorg.com"},
+			want:    Teams{{Name: "backend", Members: []Member{ivanov}}},
+		},
+		{
+			name:    "member in selected team",
+			teams:   []string{"qa"},
+			members: []string{"petrov.p"},
+			want:    Teams{{Name: "qa", Members: []Member{petrov}}},
+		},
+		{
+			name:    "unknown",
+			teams:   []string{"frontend", "mobile"},
+			members: []string{"acc1"},
+			wantErr: []error{ErrUnknownTeam, ErrUnknownMember},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := teams.Select(tt.teams, tt.members)
+			for _, wantErr := range tt.wantErr {
+				require.ErrorIs(t, err, wantErr)
+			}
+			if tt.wantErr != nil {
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestMember_Mention(t *testing.T) {
+	require.Equal(t, "@ivanov.i", Member{Name: "Ivan Ivanov", MattermostUsername: "ivanov.i"}.Mention())
+	require.Equal(t, "Ivan Ivanov", Member{Name: "Ivan Ivanov"}.Mention())
+}
@@ -0,0 +1,443 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+var ErrInvalidConfig = errors.New("invalid config")
+
+// Problem is a config problem found by validation
+type Problem struct {
+	// Line is a config file line number, 0 if unknown
+	Line int
+	// Field is a path to the field, e.g. 'teams[0].channel'
+	Field string
+	// Message describes the problem
+	Message string
+}
+
+func (p Problem) String() string {
+	var b strings.Builder
+	if p.Line > 0 {
+		fmt.Fprintf(&b, "line %d: ", p.Line)
+	}
+	if p.Field != "" {
+		fmt.Fprintf(&b, "%s: ", p.Field)
+	}
+	b.WriteString(p.Message)
+
+	return b.String()
+}
+
+// ValidationError lists all problems found in config
+type ValidationError struct {
+	Problems []Problem
+}
+
+func (e *ValidationError) Error() string {
+	ps := make([]string, 0, len(e.Problems))
+	for _, p := range e.Problems {
+		ps = append(ps, p.String())
+	}
+
+	return fmt.Sprintf("%s: %s", ErrInvalidConfig, strings.Join(ps, "; "))
+}
+
+func (e *ValidationError) Unwrap() error {
+	return ErrInvalidConfig
+}
+
+// fieldLines maps field paths to the config file lines
+type fieldLines map[string]int
+
+// line returns the line of the field or of its closest
+// parent if the field is missing in config
+func (fl fieldLines) line(path string) int {
+	for path != "" {
+		if l, ok := fl[path]; ok {
+			return l
+		}
+		i := strings.LastIndexAny(path, ".[")
+		if i < 0 {
+			break
+		}
+		path = path[:i]
+	}
+
+	return 0
+}
+
+// validator collects config problems
+type validator struct {
+	lines    fieldLines
+	problems []Problem
+}
+
+func (v *validator) addf(path, format string, args ...any) {
+	v.problems = append(v.problems, Problem{
+		Line:    v.lines.line(path),
+		Field:   path,
+		Message: fmt.Sprintf(format, args...),
+	})
+}
+
+// err returns ValidationError with problems sorted by line or nil
+func (v *validator) err() error {
+	if len(v.problems) == 0 {
+		return nil
+	}
+	sort.SliceStable(v.problems, func(i, j int) bool {
+		return v.problems[i].Line < v.problems[j].Line
+	})
+
+	return &ValidationError{Problems: v.problems}
+}
+
+// checkFields reports fields of the yaml node unknown to the type t
+// and records lines of the known ones
+func (v *validator) checkFields(n *yaml.Node, t reflect.Type, path string) {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if path != "" {
+		v.lines[path] = n.Line
+	}
+
+	switch n.Kind {
+	case yaml.DocumentNode:
+		for _, c := range n.Content {
+			v.checkFields(c, t, path)
+		}
+	case yaml.MappingNode:
+		var fields map[string]reflect.Type
+		switch t.Kind() {
+		case reflect.Struct:
+			fields = yamlFields(t)
+		case reflect.Map:
+		default:
+			return
+		}
+		for i := 0; i+1 < len(n.Content); i += 2 {
+			key, val := n.Content[i], n.Content[i+1]
+			fp := joinPath(path, key.Value)
+			ft, ok := fields[key.Value]
+			if t.Kind() == reflect.Map {
+				ft, ok = t.Elem(), true
+			}
+			if !ok {
+				v.problems = append(v.problems, Problem{Line: key.Line, Field: fp, Message: "unknown field"})
+				continue
+			}
+			v.checkFields(val, ft, fp)
+			// nested mapping starts on the next line after its key
+			v.lines[fp] = key.Line
+		}
+	case yaml.SequenceNode:
+		if t.Kind() != reflect.Slice {
+			return
+		}
+		for i, c := range n.Content {
+			v.checkFields(c, t.Elem(), fmt.Sprintf("%s[%d]", path, i))
+		}
+	}
+}
+
+// yamlFields returns struct fields types by their yaml names
+func yamlFields(t reflect.Type) map[string]reflect.Type {
+	fields := make(map[string]reflect.Type, t.NumField())
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if !f.IsExported() {
+			continue
+		}
+		name, opts, _ := strings.Cut(f.Tag.Get("yaml"), ",")
+		if name == "-" {
+			continue
+		}
+		if opts == "inline" {
+			for n, ft := range yamlFields(f.Type) {
+				fields[n] = ft
+			}
+			continue
+		}
+		if name == "" {
+			name = strings.ToLower(f.Name)
+		}
+		fields[name] = f.Type
+	}
+
+	return fields
+}
+
+func joinPath(path, name string) string {
+	if path == "" {
+		return name
+	}
+
+	return path + "." + name
+}
+
+// decodeErrors converts yaml type errors into problems
+func (v *validator) decodeErrors(err error) bool {
+	var te *yaml.TypeError
+	if !errors.As(err, &te) {
+		return false
+	}
+	for _, e := range te.Errors {
+		p := Problem{Message: e}
+		if _, serr := fmt.Sscanf(e, "line %d:", &p.Line); serr == nil {
+			_, p.Message, _ = strings.Cut(e, ": ")
+		}
+		v.problems = append(v.problems, p)
+	}
+
+	return true
+}
+
+// validate checks the config semantics
+func (v *validator) validate(p Params) {
+	v.validateJira(p.Jira)
+	if p.IsDayOff.URL != "" {
+		v.validateURL("isdayoff.url", p.IsDayOff.URL)
+	}
+	v.validateService("isdayoff", p.IsDayOff.Timeout, p.IsDayOff.Retry)
+	if p.Mattermost.URL != "" {
+		v.validateURL("notifier.mattermost.url", p.Mattermost.URL)
+		if p.Mattermost.AuthToken == "" {
+			v.addf("notifier.mattermost.auth_token", "required when url is set")
+		}
+	}
+	v.validateService("notifier.mattermost", p.Mattermost.Timeout, p.Mattermost.Retry)
+	if p.Server.PublicURL != "" {
+		v.validateURL("server.public_url", p.Server.PublicURL)
+		if p.Server.AuthToken == "" {
+			v.addf("server.auth_token", "required when public_url is set")
+		}
+		// the 'Recheck' button is sent in a message attachment
+		if !p.Mattermost.Attachments {
+			v.addf("server.public_url", "requires notifier.mattermost.attachments")
+		}
+	}
+	v.validateTeams(p.Teams)
+	for i, t := range p.Teams {
+		if t.Escalation.Enabled && p.State.Path == "" {
+			v.addf(fmt.Sprintf("teams[%d].escalation.enabled", i), "requires state.path")
+		}
+		if t.Notify.FollowsUp() && p.State.Path == "" {
+			v.addf(fmt.Sprintf("teams[%d].notify.follow_up", i), "requires state.path")
+		}
+		if t.Discovery.MattermostChannel != "" && p.Mattermost.URL == "" {
+			v.addf(fmt.Sprintf("teams[%d].discovery.mattermost_channel", i),
+				"requires notifier.mattermost.url")
+		}
+	}
+}
+
+func (v *validator) validateJira(j Jira) {
+	if j.URL == "" {
+		v.addf("jira.url", "required")
+	} else {
+		v.validateURL("jira.url", j.URL)
+	}
+
+	switch j.AuthMode {
+	case "", JiraAuthBasic:
+		if j.UserEmail == "" {
+			v.addf("jira.user_email", "required by '%s' auth mode", JiraAuthBasic)
+		}
+		if j.AuthToken == "" {
+			v.addf("jira.auth_token", "required by '%s' auth mode", JiraAuthBasic)
+		}
+	case JiraAuthBearer:
+		if j.AuthToken == "" {
+			v.addf("jira.auth_token", "required by '%s' auth mode", JiraAuthBearer)
+		}
+	case JiraAuthOAuth2:
+		if j.OAuth2.TokenURL == "" {
+			v.addf("jira.oauth2.token_url", "required by '%s' auth mode", JiraAuthOAuth2)
+		} else {
+			v.validateURL("jira.oauth2.token_url", j.OAuth2.TokenURL)
+		}
+		if j.OAuth2.ClientID == "" {
+			v.addf("jira.oauth2.client_id", "required by '%s' auth mode", JiraAuthOAuth2)
+		}
+		if j.OAuth2.ClientSecret == "" {
+			v.addf("jira.oauth2.client_secret", "required by '%s' auth mode", JiraAuthOAuth2)
+		}
+	default:
+		v.addf("jira.auth_mode", "unknown mode '%s', expected %s, %s or %s",
+			j.AuthMode, JiraAuthBasic, JiraAuthBearer, JiraAuthOAuth2)
+	}
+
+	v.validateService("jira", j.Timeout, j.Retry)
+}
+
+func (v *validator) validateService(path string, timeout time.Duration, r Retry) {
+	if timeout < 0 {
+		v.addf(path+".timeout", "must not be negative")
+	}
+	if r.MaxAttempts < 0 {
+		v.addf(path+".retry.max_attempts", "must not be negative")
+	}
+	if r.InitialBackoff < 0 {
+		v.addf(path+".retry.initial_backoff", "must not be negative")
+	}
+	if r.MaxBackoff < 0 {
+		v.addf(path+".retry.max_backoff", "must not be negative")
+	}
+	if r.MaxRetryAfter < 0 {
+		v.addf(path+".retry.max_retry_after", "must not be negative")
+	}
+}
+
+func (v *validator) validateURL(path, raw string) {
+	u, err := url.Parse(raw)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		v.addf(path, "'%s' is not a valid http or https URL", raw)
+	}
+}
+
+func (v *validator) validateTeams(teams Teams) {
+	names := make(map[string]bool, len(teams))
+	for i, t := range teams {
+		tp := fmt.Sprintf("teams[%d]", i)
+		switch {
+		case t.Name == "":
+			v.addf(tp+".name", "required")
+		case names[t.Name]:
+			v.addf(tp+".name", "duplicate team name '%s'", t.Name)
+		}
+		names[t.Name] = true
+
+		if t.Channel == "" {
+			v.addf(tp+".channel", "required")
+		}
+		if t.Timezone != "" {
+			if _, err := time.LoadLocation(t.Timezone); err != nil {
+				v.addf(tp+".timezone", "unknown time zone '%s'", t.Timezone)
+			}
+		}
+
+		switch t.Notify.Policy {
+		case "", NotifyAlways, NotifyOnlyWhenMissing, NotifyFridaySummary:
+		default:
+			v.addf(tp+".notify.policy", "unknown policy '%s', expected %s, %s or %s",
+				t.Notify.Policy, NotifyAlways, NotifyOnlyWhenMissing, NotifyFridaySummary)
+		}
+		if qh := t.Notify.QuietHours; qh.From != "" || qh.To != "" {
+			if _, err := time.Parse(quietHoursFormat, qh.From); err != nil {
+				v.addf(tp+".notify.quiet_hours.from", "'%s' is not a time, expected HH:MM", qh.From)
+			}
+			if _, err := time.Parse(quietHoursFormat, qh.To); err != nil {
+				v.addf(tp+".notify.quiet_hours.to", "'%s' is not a time, expected HH:MM", qh.To)
+			}
+		}
+		switch t.Notify.FollowUp {
+		case "", FollowUpPost, FollowUpEdit, FollowUpThread:
+		default:
+			v.addf(tp+".notify.follow_up", "unknown follow up '%s', expected %s, %s or %s",
+				t.Notify.FollowUp, FollowUpPost, FollowUpEdit, FollowUpThread)
+		}
+
+		if t.Escalation.ChannelFrom < 0 {
+			v.addf(tp+".escalation.channel_from", "must not be negative")
+		}
+		if t.Escalation.ManagerFrom < 0 {
+			v.addf(tp+".escalation.manager_from", "must not be negative")
+		}
+		if t.Escalation.ChannelDay() >= t.Escalation.ManagerDay() {
+			v.addf(tp+".escalation.channel_from", "must be less than manager_from (%d)", t.Escalation.ManagerDay())
+		}
+
+		if t.CommentRules.MinLength < 0 {
+			v.addf(tp+".comment_rules.min_length", "must not be negative")
+		}
+		if t.CommentRules.Pattern != "" {
+			if _, err := regexp.Compile(t.CommentRules.Pattern); err != nil {
+				v.addf(tp+".comment_rules.pattern", "bad regular expression: %s", err)
+			}
+		}
+
+		if t.Scope.JQL != "" {
+			if err := checkJQLCondition(t.Scope.JQL); err != nil {
+				v.addf(tp+".scope.jql", "%s", err)
+			}
+		}
+
+		if (t.Discovery.JiraProject == "") != (t.Discovery.JiraRole == "") {
+			v.addf(tp+".discovery", "jira_project and jira_role must be set together")
+		}
+
+		ids := make(map[string]bool, len(t.Members))
+		for j, m := range t.Members {
+			mp := fmt.Sprintf("%s.members[%d]", tp, j)
+			if m.Name == "" {
+				v.addf(mp+".name", "required")
+			}
+			switch {
+			case m.JiraAccID == "":
+				v.addf(mp+".jira_account_id", "required, run 'resolve-members' to find it by email or name")
+			case ids[m.JiraAccID]:
+				v.addf(mp+".jira_account_id", "duplicate member id '%s' in team", m.JiraAccID)
+			}
+			ids[m.JiraAccID] = true
+		}
+	}
+}
+
+// jqlOrderBy matches JQL ORDER BY clause
+var jqlOrderBy = regexp.MustCompile(`(?i)\border\s+by\b`)
+
+// checkJQLCondition checks the JQL can be joined with other conditions:
+// its parentheses and quotes are balanced and it has no ORDER BY clause
+func checkJQLCondition(jql string) error {
+	var (
+		outside strings.Builder
+		quote   rune
+		escaped bool
+		depth   int
+	)
+	for _, r := range jql {
+		switch {
+		case escaped:
+			escaped = false
+		case quote != 0 && r == '\\':
+			escaped = true
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			}
+		case r == '"' || r == '\'':
+			quote = r
+		case r == '(':
+			depth++
+		case r == ')':
+			if depth--; depth < 0 {
+				return errors.New("unbalanced parentheses")
+			}
+		}
+		if quote == 0 {
+			outside.WriteRune(r)
+		}
+	}
+
+	switch {
+	case quote != 0:
+		return errors.New("unterminated string")
+	case depth != 0:
+		return errors.New("unbalanced parentheses")
+	case jqlOrderBy.MatchString(outside.String()):
+		return errors.New("ORDER BY is not allowed in condition")
+	}
+
+	return nil
+}
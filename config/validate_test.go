@@ -0,0 +1,114 @@
+package config
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestReadConfigValidation(t *testing.T) {
+	path := writeFile(t, "config.yml", `jira:
+  url: myorg.atlassian.net
+  auth_mode: oauth2
+  oauth2:
+    client_id: client
+  retry:
+    max_attempts: many
+notifier:
+  mattermost:
+    url: https://mattermost.myorg.com
+teams:
+  - name: backend
+    timezone: Mars/Olympus
+    members:
+      - name: Ivan Ivanov
+        jira_acount_id: acc1
+      - name: Petr Petrov
+        jira_account_id: acc2
+      - name: Petr Petrov
+        jira_account_id: acc2
+  - name: backend
+    channel: ch2
+    discovery:
+      jira_project: PRJ
+    comment_rules:
+      pattern: "[A-Z+-"
+    notify:
+      policy: never
+      quiet_hours:
+        from: "22:00"
+      follow_up: edits
+    escalation:
+      channel_from: 4
+server:
+  public_url: ts-notifier.myorg.com
+`)
+
+	_, err := ReadConfig(context.Background(), path)
+	require.True(t, errors.Is(err, ErrInvalidConfig))
+
+	var ve *ValidationError
+	require.True(t, errors.As(err, &ve))
+
+	var got []string
+	for _, p := range ve.Problems {
+		got = append(got, p.String())
+	}
+	require.Equal(t, []string{
+		"line 2: jira.url: 'myorg.atlassian.net' is not a valid http or https URL",
+		"line 4: jira.oauth2.token_url: required by 'oauth2' auth mode",
+		"line 4: jira.oauth2.client_secret: required by 'oauth2' auth mode",
+		"line 7: cannot unmarshal !!str `many` into int",
+		"line 9: notifier.mattermost.auth_token: required when url is set",
+		"line 12: teams[0].channel: required",
+		"line 13: teams[0].timezone: unknown time zone 'Mars/Olympus'",
+		"line 15: teams[0].members[0].jira_account_id: required, run 'resolve-members' to find it by email or name",
+		"line 16: teams[0].members[0].jira_acount_id: unknown field",
+		"line 20: teams[0].members[2].jira_account_id: duplicate member id 'acc2' in team",
+		"line 21: teams[1].name: duplicate team name 'backend'",
+		"line 23: teams[1].discovery: jira_project and jira_role must be set together",
+		"line 26: teams[1].comment_rules.pattern: bad regular expression: error parsing regexp: missing closing ]: `[A-Z+-`",
+		"line 28: teams[1].notify.policy: unknown policy 'never', expected always, only-when-missing or friday-summary",
+		"line 29: teams[1].notify.quiet_hours.to: '' is not a time, expected HH:MM",
+		"line 31: teams[1].notify.follow_up: unknown follow up 'edits', expected post, edit or thread",
+		"line 33: teams[1].escalation.channel_from: must be less than manager_from (3)",
+		"line 34: server.auth_token: required when public_url is set",
+		"line 35: server.public_url: 'ts-notifier.myorg.com' is not a valid http or https URL",
+		"line 35: server.public_url: requires notifier.mattermost.attachments",
+	}, got)
+}
+
+func Test_fieldLines(t *testing.T) {
+	fl := fieldLines{"teams": 10, "teams[0]": 11, "teams[0].members[1]": 15}
+	require.Equal(t, 15, fl.line("teams[0].members[1].name"))
+	require.Equal(t, 11, fl.line("teams[0].channel"))
+	require.Equal(t, 10, fl.line("teams[2].name"))
+	require.Equal(t, 0, fl.line("jira.url"))
+}
+
+func TestCheckJQLCondition(t *testing.T) {
+	tests := []struct {
+		jql     string
+		wantErr string
+	}{
+		{jql: `component = Backend OR labels in ("a(", 'b)')`},
+		{jql: `summary ~ "order by \"date\""`},
+		{jql: `component = Backend ORDER BY created`, wantErr: "ORDER BY is not allowed in condition"},
+		{jql: `component = Backend order  by created`, wantErr: "ORDER BY is not allowed in condition"},
+		{jql: `component = Backend) OR (project = OPS`, wantErr: "unbalanced parentheses"},
+		{jql: `(component = Backend`, wantErr: "unbalanced parentheses"},
+		{jql: `summary ~ "open`, wantErr: "unterminated string"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.jql, func(t *testing.T) {
+			err := checkJQLCondition(tt.jql)
+			if tt.wantErr == "" {
+				require.NoError(t, err)
+				return
+			}
+			require.EqualError(t, err, tt.wantErr)
+		})
+	}
+}
@@ -0,0 +1,122 @@
+package app
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/duke0x/ts-notifier/config"
+)
+
+// RunSummary stores results of the teams processing run
+type RunSummary struct {
+	// Command is a run command, config.CmdNotify for the daily check
+	Command string
+	Date    time.Time
+	// Teams is a number of processed teams
+	Teams int
+	// Failed are names of the failed teams
+	Failed []string
+	// Sent is a number of notifications sent to team channels
+	Sent int
+}
+
+func (rs RunSummary) String() string {
+	command := rs.Command
+	if command == config.CmdNotify {
+		command = "notify"
+	}
+
+	var b strings.Builder
+	b.WriteString("Итоги запуска " + command + " за " + rs.Date.Format("2006.01.02") + ": " +
+		"обработано команд " + strconv.Itoa(rs.Teams-len(rs.Failed)) + " из " + strconv.Itoa(rs.Teams) +
+		", отправлено уведомлений " + strconv.Itoa(rs.Sent) +
+		", ошибок " + strconv.Itoa(len(rs.Failed)) + ".")
+	if len(rs.Failed) > 0 {
+		b.WriteString("\nКоманды с ошибками: " + strings.Join(rs.Failed, ", ") + ".")
+	}
+
+	return b.String()
+}
+
+// Alert sends the failure description with the error chain to the admin channel
+// if it is set. It is sent even if ctx is canceled, e.g. by the run timeout.
+func Alert(ctx context.Context, n Notifier, admin config.Admin, title string, err error) error {
+	if admin.Channel == "" {
+		return nil
+	}
+
+	return n.Notify(context.WithoutCancel(ctx), admin.Channel, ":warning: "+title+"\n"+ErrorChain(err))
+}
+
+// Alert sends the failure description with the error chain to the admin channel
+func (app *App) Alert(ctx context.Context, title string, err error) error {
+	return Alert(ctx, app.notifier, app.params.Admin, title, err)
+}
+
+// notifyAdmin sends the message to the admin channel if it is set
+func (app *App) notifyAdmin(ctx context.Context, message string) error {
+	if app.params.Admin.Channel == "" {
+		return nil
+	}
+
+	return app.notifier.Notify(ctx, app.params.Admin.Channel, message)
+}
+
+// ErrorChain returns error with its causes, one wrapping level per line.
+// Every level shows its own message only, joined errors are listed as siblings.
+func ErrorChain(err error) string {
+	var b strings.Builder
+	writeChain(&b, err, 0)
+
+	return strings.TrimSuffix(b.String(), "\n")
+}
+
+func writeChain(b *strings.Builder, err error, depth int) {
+	if err == nil {
+		return
+	}
+
+	var causes []error
+	switch e := err.(type) {
+	case interface{ Unwrap() []error }:
+		causes = e.Unwrap()
+	case interface{ Unwrap() error }:
+		if c := e.Unwrap(); c != nil {
+			causes = []error{c}
+		}
+	}
+
+	// joined errors have no own message
+	if _, joined := err.(interface{ Unwrap() []error }); joined && len(causes) > 1 && isJoin(err, causes) {
+		for _, c := range causes {
+			writeChain(b, c, depth)
+		}
+		return
+	}
+
+	msg := err.Error()
+	for _, c := range causes {
+		msg = strings.TrimSuffix(msg, ": "+c.Error())
+	}
+	b.WriteString(strings.Repeat("  ", depth) + "- " + msg + "\n")
+	for _, c := range causes {
+		// a sentinel error already shown as the message prefix, e.g. '%w: %w'
+		if c.Error() == msg {
+			continue
+		}
+		writeChain(b, c, depth+1)
+	}
+}
+
+// isJoin reports whether the error message is its causes messages
+// joined with new lines, as errors.Join does
+func isJoin(err error, causes []error) bool {
+	msgs := make([]string, 0, len(causes))
+	for _, c := range causes {
+		msgs = append(msgs, c.Error())
+	}
+
+	return err.Error() == strings.Join(msgs, "\n")
+}
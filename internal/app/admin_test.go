@@ -0,0 +1,72 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/duke0x/ts-notifier/config"
+	mock_day_type_fetcher "github.com/duke0x/ts-notifier/mock/day_type_fetcher"
+	mock_notifier "github.com/duke0x/ts-notifier/mock/notifier"
+	"github.com/duke0x/ts-notifier/model"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestErrorChain(t *testing.T) {
+	errDown := errors.New("service is down")
+	err := fmt.Errorf("%w: %w", ErrPartialFailure, errors.Join(
+		fmt.Errorf("checking time spends: %w", errDown),
+		errors.New("notify failed"),
+	))
+
+	require.Equal(t, "- some teams failed\n"+
+		"  - checking time spends\n"+
+		"    - service is down\n"+
+		"  - notify failed", ErrorChain(err))
+	require.Equal(t, "- service is down", ErrorChain(errDown))
+}
+
+func TestRunSummary_String(t *testing.T) {
+	day := time.Date(2023, 9, 1, 0, 0, 0, 0, time.UTC)
+
+	require.Equal(t, "Итоги запуска notify за 2023.09.01: обработано команд 2 из 2, "+
+		"отправлено уведомлений 2, ошибок 0.",
+		RunSummary{Date: day, Teams: 2, Sent: 2}.String())
+	require.Equal(t, "Итоги запуска stats за 2023.09.01: обработано команд 1 из 3, "+
+		"отправлено уведомлений 1, ошибок 2.\nКоманды с ошибками: backend, mobile.",
+		RunSummary{Command: config.CmdStats, Date: day, Teams: 3, Failed: []string{"backend", "mobile"}, Sent: 1}.String())
+}
+
+func TestApp_RunSummary(t *testing.T) {
+	ctrl := gomock.NewController(t)
+
+	ctx := context.Background()
+	day := time.Date(2023, 9, 2, 0, 0, 0, 0, time.UTC)
+	dtf := mock_day_type_fetcher.NewMockDayTypeFetcher(ctrl)
+	n := mock_notifier.NewMockNotifier(ctrl)
+
+	app := NewCliApp(
+		config.Args{Date: day},
+		config.Params{
+			Notifier: config.Notifier{Admin: config.Admin{Channel: "admins", Summary: true}},
+			Teams:    []config.Team{{Name: "team1", Channel: "channel-team1"}},
+		},
+		dtf,
+		nil,
+		n,
+		nil,
+		nil,
+		nil,
+		nil,
+	)
+
+	// non-working day is not a failure to alert about
+	dtf.EXPECT().FetchDayType(ctx, day).Return(model.NoWorkDay, nil)
+	n.EXPECT().Notify(ctx, "admins", "Итоги запуска notify за 2023.09.02: обработано команд 1 из 1, "+
+		"отправлено уведомлений 0, ошибок 0.").Return(nil)
+
+	require.Error(t, app.Run(ctx))
+}
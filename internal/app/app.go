@@ -1,23 +1,53 @@
 package app
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"log/slog"
+	"sync/atomic"
+	"time"
 
 	"github.com/duke0x/ts-notifier/config"
+	"github.com/duke0x/ts-notifier/internal/metrics"
+	"github.com/duke0x/ts-notifier/model"
 	"github.com/duke0x/ts-notifier/tscalculator"
 )
 
+// ErrPartialFailure is returned when some teams are processed and others failed
+var ErrPartialFailure = errors.New("some teams failed")
+
 //go:generate mockgen -package=mock_notifier -destination=../../mock/notifier/mock_notifier.go github.com/duke0x/ts-notifier/internal/app Notifier
 type Notifier interface {
-	Notify(channel, message string) error
+	Notify(ctx context.Context, channel, message string) error
+	// NotifyUser sends the direct message to the user with the username
+	NotifyUser(ctx context.Context, username, message string) error
+	// Post sends the message to the channel, in the thread of the rootID
+	// post if it is not empty, and returns the sent post identifier
+	Post(ctx context.Context, channel, rootID string, msg model.Message) (string, error)
+	// UpdatePost replaces the message of the sent post
+	UpdatePost(ctx context.Context, postID string, msg model.Message) error
+}
+
+// TeamResolver adds members discovered in Jira and Mattermost to the team
+type TeamResolver interface {
+	ResolveTeam(ctx context.Context, team config.Team) (config.Team, error)
 }
 
 type App struct {
+	// sent is a number of notifications sent to team channels
+	sent        atomic.Int64
 	args        config.Args
 	params      config.Params
 	dtFetcher   tscalculator.DayTypeFetcher
 	logsFetcher tscalculator.WorkLogFetcher
 	notifier    Notifier
+	store       StateStore
+	resolver    TeamResolver
+	metrics     *metrics.Metrics
+	logger      *slog.Logger
+	// now returns current time, it is replaced in tests
+	now func() time.Time
 }
 
 func NewCliApp(
@@ -26,43 +56,283 @@ func NewCliApp(
 	dtFetcher tscalculator.DayTypeFetcher,
 	logsFetcher tscalculator.WorkLogFetcher,
 	notifier Notifier,
+	store StateStore,
+	resolver TeamResolver,
+	m *metrics.Metrics,
+	logger *slog.Logger,
 ) *App {
+	if logger == nil {
+		logger = slog.Default()
+	}
+
 	return &App{
 		args:        args,
 		params:      params,
 		dtFetcher:   dtFetcher,
 		logsFetcher: logsFetcher,
 		notifier:    notifier,
+		store:       store,
+		resolver:    resolver,
+		metrics:     m,
+		logger:      logger,
+		now:         time.Now,
+	}
+}
+
+func (app *App) Run(ctx context.Context) (err error) {
+	return app.eachTeam(ctx, func(team config.Team) error {
+		return app.runPolicy(ctx, app.args.Date, team)
+	})
+}
+
+// runPolicy sends the team daily report according to the team
+// notification policy, nothing is sent within quiet hours
+func (app *App) runPolicy(ctx context.Context, day time.Time, team config.Team) error {
+	if now := app.now().In(team.Location()); team.Notify.QuietHours.Contains(now) {
+		app.logger.InfoContext(ctx, "notification skipped within quiet hours",
+			"team", team.Name, "time", now.Format("15:04"))
+		return nil
 	}
+
+	switch team.Notify.Policy {
+	case config.NotifyFridaySummary:
+		if day.Weekday() != time.Friday {
+			app.logger.InfoContext(ctx, "notification skipped until friday summary", "team", team.Name)
+			return nil
+		}
+		return app.runWeekSummary(ctx, day, team)
+	case config.NotifyOnlyWhenMissing:
+		return app.runTeam(ctx, day, team, true)
+	default:
+		return app.runTeam(ctx, day, team, false)
+	}
+}
+
+// runWeekSummary sends the team stats from Monday to the day
+func (app *App) runWeekSummary(ctx context.Context, day time.Time, team config.Team) error {
+	monday := day.AddDate(0, 0, -(int(day.Weekday())+6)%7)
+	tsc := tscalculator.New(app.dtFetcher, app.logsFetcher, app.logger)
+	stats, err := tsc.CalcPeriodStats(ctx, monday, day, team)
+	if err != nil {
+		return fmt.Errorf("calculating team '%s' week stats: %w", team.Name, err)
+	}
+
+	if err := app.notify(ctx, team.Channel, stats.Report()); err != nil {
+		return fmt.Errorf("notify about team '%s' week stats: %w", team.Name, err)
+	}
+
+	return nil
 }
 
-func (app *App) Run() (err error) {
-	tsc := tscalculator.New(app.dtFetcher, app.logsFetcher)
+// eachTeam runs fn for every team with discovered members and only
+// selected members, teams without selected members are skipped.
+// A failed team does not stop others, discovery errors fail the team too.
+// Errors are recorded in metrics.
+// Failures are sent to the admin channel and returned joined.
+// ErrPartialFailure is wrapped if not all working teams failed.
+// Non-working day is returned only if it is for every team.
+// The run summary is sent to the admin channel when enabled,
+// admin notification errors are logged and not returned.
+func (app *App) eachTeam(ctx context.Context, fn func(team config.Team) error) error {
+	app.sent.Store(0)
+	var (
+		errs     []error
+		offErrs  []error
+		failed   []string
+		teamsNum int
+		matched  = make(map[string]bool, len(app.args.Members))
+	)
 	for _, team := range app.params.Teams {
-		teamSpends, err := tsc.CalcDailyTimeSpends(app.args.Date, team)
+		team, err := app.resolveTeam(ctx, team)
+		if err == nil {
+			var found []string
+			team, found = team.SelectMembers(app.args.Members)
+			for _, sel := range found {
+				matched[sel] = true
+			}
+			if len(team.Members) == 0 && len(app.args.Members) > 0 {
+				continue
+			}
+			err = fn(team)
+		}
+		teamsNum++
+		app.metrics.ObserveError(err)
+		if err == nil {
+			continue
+		}
+		if errors.Is(err, tscalculator.ErrNonWorkingDay) {
+			offErrs = append(offErrs, err)
+			continue
+		}
+
+		errs = append(errs, err)
+		failed = append(failed, team.Name)
+		app.logger.ErrorContext(ctx, "team processing failed", "team", team.Name, "error", err)
+		if nerr := app.Alert(ctx, "Не удалось обработать команду "+team.Name, err); nerr != nil {
+			app.logger.ErrorContext(ctx, "notify admins about team failure", "team", team.Name, "error", nerr)
+		}
+	}
+
+	summary := RunSummary{
+		Command: app.args.Command,
+		Date:    app.args.Date,
+		Teams:   teamsNum,
+		Failed:  failed,
+		Sent:    int(app.sent.Load()),
+	}
+	if app.params.Admin.Summary {
+		if nerr := app.notifyAdmin(ctx, summary.String()); nerr != nil {
+			app.logger.ErrorContext(ctx, "notify admins about run summary", "error", nerr)
+		}
+	}
+
+	// member selectors are checked against discovered members
+	for _, sel := range app.args.Members {
+		if !matched[sel] {
+			errs = append(errs, fmt.Errorf("%w '%s'", config.ErrUnknownMember, sel))
+		}
+	}
+
+	switch {
+	case len(errs) == 0 && len(offErrs) == teamsNum:
+		return errors.Join(offErrs...)
+	case len(failed) == 0:
+		return errors.Join(errs...)
+	case len(failed) < teamsNum-len(offErrs):
+		return fmt.Errorf("%w: %w", ErrPartialFailure, errors.Join(errs...))
+	default:
+		return errors.Join(errs...)
+	}
+}
+
+// resolveTeam returns the team with discovered members
+func (app *App) resolveTeam(ctx context.Context, team config.Team) (config.Team, error) {
+	if app.resolver == nil {
+		return team, nil
+	}
+
+	resolved, err := app.resolver.ResolveTeam(ctx, team)
+	if err != nil {
+		return team, fmt.Errorf("resolving team '%s' members: %w", team.Name, err)
+	}
+
+	return resolved, nil
+}
+
+// notify sends the message to the team channel and counts it
+func (app *App) notify(ctx context.Context, channel, message string) error {
+	if err := app.notifier.Notify(ctx, channel, message); err != nil {
+		return err
+	}
+	app.sent.Add(1)
+
+	return nil
+}
+
+// RunTeam checks remaining time spends of the team for the day
+// and sends the report to the team channel.
+func (app *App) RunTeam(ctx context.Context, day time.Time, team config.Team) error {
+	err := app.runTeam(ctx, day, team, false)
+	app.metrics.ObserveError(err)
+
+	return err
+}
+
+// runTeam sends the team daily report, onlyMissing skips
+// the report if all team members have logged their time
+func (app *App) runTeam(ctx context.Context, day time.Time, team config.Team, onlyMissing bool) error {
+	teamSpends, err := app.calcTeam(ctx, day, team)
+	if err != nil {
+		return err
+	}
+
+	// escalation reminders do not stop the team report
+	var escErr error
+	if team.Escalation.Enabled && app.store != nil {
+		escErr = app.escalate(ctx, day, team, teamSpends)
+	}
+
+	if teamSpends.RemainSpend() == 0 {
+		app.logger.InfoContext(ctx, "all team members have written their time logs", "team", team.Name)
+		// the earlier report of the day is still followed up with the good news
+		if onlyMissing && app.followUpPost(team, day) == "" {
+			return escErr
+		}
+	}
+
+	if err := app.sendReport(ctx, day, team, app.reportMessage(day, team, teamSpends)); err != nil {
+		return fmt.Errorf(
+			"notify about remaining team '%s' time spends: %w",
+			team.Name,
+			err,
+		)
+	}
+	app.logger.InfoContext(ctx, "notification sent", "team", team.Name, "channel", team.Channel)
+
+	return escErr
+}
+
+// calcTeam calculates the team time spends for the day
+// and sets member remaining time metrics
+func (app *App) calcTeam(ctx context.Context, day time.Time, team config.Team) (tscalculator.TeamRemainSpends, error) {
+	tsc := tscalculator.New(app.dtFetcher, app.logsFetcher, app.logger)
+	teamSpends, err := tsc.CalcDailyTimeSpends(ctx, day, team)
+	if err != nil {
+		return nil, fmt.Errorf("checking time spends: %w", err)
+	}
+
+	for _, mrs := range teamSpends {
+		app.metrics.SetRemainSpend(team.Name, mrs.Member.JiraAccID, mrs.RemainSpend)
+	}
+
+	return teamSpends, nil
+}
+
+// RunStats calculates time spends compliance of every team
+// for the period and sends the summary to the team channel.
+func (app *App) RunStats(ctx context.Context) error {
+	tsc := tscalculator.New(app.dtFetcher, app.logsFetcher, app.logger)
+
+	return app.eachTeam(ctx, func(team config.Team) error {
+		stats, err := tsc.CalcPeriodStats(ctx, app.args.From, app.args.To, team)
 		if err != nil {
-			return fmt.Errorf("checking time spends: %w", err)
+			return fmt.Errorf("calculating team '%s' stats: %w", team.Name, err)
 		}
 
-		if teamSpends.RemainSpend() == 0 {
-			fmt.Printf(
-				"all members of team '%s' has written their timelogs\n",
+		if err := app.notify(ctx, team.Channel, stats.Report()); err != nil {
+			return fmt.Errorf(
+				"notify about team '%s' stats: %w",
 				team.Name,
+				err,
 			)
 		}
 
-		if err := app.notifier.Notify(
-			team.Channel,
-			teamSpends.Report(app.args.Date),
-		); err != nil {
+		return nil
+	})
+}
+
+// RunLate searches work logs of every team written retroactively
+// in the period and sends the list to the team channel.
+func (app *App) RunLate(ctx context.Context) error {
+	const hoursPerDay = 24
+
+	threshold := time.Duration(app.args.LateDays) * hoursPerDay * time.Hour
+	tsc := tscalculator.New(app.dtFetcher, app.logsFetcher, app.logger)
+
+	return app.eachTeam(ctx, func(team config.Team) error {
+		tll, err := tsc.CalcLateWorkLogs(ctx, app.args.From, app.args.To, team, threshold)
+		if err != nil {
+			return fmt.Errorf("searching team '%s' late work logs: %w", team.Name, err)
+		}
+
+		if err := app.notify(ctx, team.Channel, tll.Report()); err != nil {
 			return fmt.Errorf(
-				"notify about remaining team '%s' time spends: %w",
+				"notify about team '%s' late work logs: %w",
 				team.Name,
 				err,
 			)
 		}
-		fmt.Printf("notification for team '%s' sent\n", team.Name)
-	}
 
-	return nil
+		return nil
+	})
 }
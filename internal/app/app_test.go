@@ -2,13 +2,17 @@ package app
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"github.com/duke0x/ts-notifier/client"
 	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/http/httptest"
 	"testing"
 	"time"
 
 	"github.com/duke0x/ts-notifier/config"
+	"github.com/duke0x/ts-notifier/internal/metrics"
 	mock_day_type_fetcher "github.com/duke0x/ts-notifier/mock/day_type_fetcher"
 	mock_notifier "github.com/duke0x/ts-notifier/mock/notifier"
 	mock_worklog_fetcher "github.com/duke0x/ts-notifier/mock/work_log_fetcher"
@@ -48,6 +52,10 @@ func TestApp_RunSuccess(t *testing.T) {
 			mock_day_type_fetcher.NewMockDayTypeFetcher(ctrl),
 			mock_worklog_fetcher.NewMockWorkLogFetcher(ctrl),
 			mock_notifier.NewMockNotifier(ctrl),
+			nil,
+			nil,
+			nil,
+			nil,
 		)
 
 		dtf, ok := app.dtFetcher.(*mock_day_type_fetcher.MockDayTypeFetcher)
@@ -67,6 +75,7 @@ func TestApp_RunSuccess(t *testing.T) {
 					ctx,
 					model.User(member.JiraAccID),
 					day,
+					"",
 				).Return(issues, nil)
 
 				dayStart := day.Truncate(time.Hour * 24).UTC()
@@ -83,16 +92,18 @@ func TestApp_RunSuccess(t *testing.T) {
 				trs = append(trs, tscalculator.MemberRemainSpend{
 					Member:      member,
 					RemainSpend: 1 * time.Hour,
+					WorkLogs:    wl,
+					Issues:      issues,
 				})
 			}
 
 			n, ok := app.notifier.(*mock_notifier.MockNotifier)
 			require.Equal(t, true, ok)
 
-			n.EXPECT().Notify(team.Channel, trs.Report(app.args.Date)).Return(nil)
+			n.EXPECT().Post(ctx, team.Channel, "", trs.Message(app.args.Date, nil)).Return("post1", nil)
 		}
 
-		err := app.Run()
+		err := app.Run(ctx)
 		require.NoError(t, err)
 	})
 }
@@ -126,6 +137,10 @@ func TestApp_RunNotifierError(t *testing.T) {
 		mock_day_type_fetcher.NewMockDayTypeFetcher(ctrl),
 		mock_worklog_fetcher.NewMockWorkLogFetcher(ctrl),
 		mock_notifier.NewMockNotifier(ctrl),
+		nil,
+		nil,
+		nil,
+		nil,
 	)
 
 	dtf, ok := app.dtFetcher.(*mock_day_type_fetcher.MockDayTypeFetcher)
@@ -145,6 +160,7 @@ func TestApp_RunNotifierError(t *testing.T) {
 				ctx,
 				model.User(member.JiraAccID),
 				day,
+				"",
 			).Return(issues, nil)
 
 			dayStart := day.Truncate(time.Hour * 24).UTC()
@@ -161,16 +177,18 @@ func TestApp_RunNotifierError(t *testing.T) {
 			trs = append(trs, tscalculator.MemberRemainSpend{
 				Member:      member,
 				RemainSpend: 1 * time.Hour,
+				WorkLogs:    wl,
+				Issues:      issues,
 			})
 		}
 
 		n, ok := app.notifier.(*mock_notifier.MockNotifier)
 		require.Equal(t, true, ok)
 
-		n.EXPECT().Notify(team.Channel, trs.Report(app.args.Date)).Return(fmt.Errorf("service unavailable"))
+		n.EXPECT().Post(ctx, team.Channel, "", trs.Message(app.args.Date, nil)).Return("", fmt.Errorf("service unavailable"))
 	}
 
-	err := app.Run()
+	err := app.Run(ctx)
 	require.EqualError(t, err, "notify about remaining team '"+
 		app.params.Teams[0].Name+"' time spends: "+"service unavailable")
 }
@@ -204,6 +222,10 @@ func TestApp_RunCalcDailyTimeSpendsError(t *testing.T) {
 		mock_day_type_fetcher.NewMockDayTypeFetcher(ctrl),
 		mock_worklog_fetcher.NewMockWorkLogFetcher(ctrl),
 		mock_notifier.NewMockNotifier(ctrl),
+		nil,
+		nil,
+		nil,
+		nil,
 	)
 
 	dtf, ok := app.dtFetcher.(*mock_day_type_fetcher.MockDayTypeFetcher)
@@ -216,6 +238,305 @@ func TestApp_RunCalcDailyTimeSpendsError(t *testing.T) {
 		app.args.Date.Format("20060102"),
 		client.ErrServiceUnavailable.Error(),
 	)
-	err := app.Run()
+	err := app.Run(ctx)
 	require.EqualError(t, err, fetchDayTypeError)
 }
+
+func TestApp_RunJiraErrorNotNotified(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ctx := context.Background()
+	day := time.Now().Truncate(60 * time.Second)
+	member := config.Member{Name: "Ivan Ivanov", JiraAccID: "18gdasid123123jas"}
+
+	dtf := mock_day_type_fetcher.NewMockDayTypeFetcher(ctrl)
+	wlf := mock_worklog_fetcher.NewMockWorkLogFetcher(ctrl)
+	// notifier has no expectations: a report must not be sent
+	n := mock_notifier.NewMockNotifier(ctrl)
+
+	app := NewCliApp(
+		config.Args{Date: day},
+		config.Params{Teams: []config.Team{{
+			Name:    "team1",
+			Channel: "channel-team1",
+			Members: []config.Member{member},
+		}}},
+		dtf,
+		wlf,
+		n,
+		nil,
+		nil,
+		nil,
+		nil,
+	)
+
+	jiraErr := &client.JiraError{StatusCode: 401, Err: client.ErrUnauthorized}
+	dtf.EXPECT().FetchDayType(ctx, day).Return(model.WorkDay, nil)
+	wlf.EXPECT().UserWorkedIssuesByDate(ctx, model.User(member.JiraAccID), day, "").Return(nil, jiraErr)
+
+	err := app.Run(ctx)
+	require.ErrorIs(t, err, client.ErrUnauthorized)
+}
+
+func TestApp_RunTeamFailureIsolated(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ctx := context.Background()
+	day := time.Date(2023, 9, 1, 0, 0, 0, 0, time.UTC)
+	broken := config.Member{Name: "Ivan Ivanov", JiraAccID: "bad-id"}
+	member := config.Member{Name: "Petr Petrov", JiraAccID: "acc2", MattermostUsername: "petrov.p"}
+
+	dtf := mock_day_type_fetcher.NewMockDayTypeFetcher(ctrl)
+	wlf := mock_worklog_fetcher.NewMockWorkLogFetcher(ctrl)
+	n := mock_notifier.NewMockNotifier(ctrl)
+
+	app := NewCliApp(
+		config.Args{Date: day},
+		config.Params{
+			Notifier: config.Notifier{Admin: config.Admin{Channel: "admins"}},
+			Teams: []config.Team{
+				{Name: "team1", Channel: "channel-team1", Members: []config.Member{broken}},
+				{Name: "team2", Channel: "channel-team2", Members: []config.Member{member}},
+			},
+		},
+		dtf,
+		wlf,
+		n,
+		nil,
+		nil,
+		nil,
+		nil,
+	)
+
+	jiraErr := &client.JiraError{StatusCode: 400, Err: client.ErrBadJQL}
+	dtf.EXPECT().FetchDayType(ctx, day).Return(model.WorkDay, nil).Times(2)
+	wlf.EXPECT().UserWorkedIssuesByDate(ctx, model.User("bad-id"), day, "").Return(nil, jiraErr)
+	wlf.EXPECT().UserWorkedIssuesByDate(ctx, model.User("acc2"), day, "").Return(nil, nil)
+	wlf.EXPECT().WorkLogsPerIssues(ctx, model.User("acc2"), day, day.Add(24*time.Hour-time.Second), nil).
+		Return(nil, nil)
+
+	// admins are notified even if the run context is canceled
+	n.EXPECT().Notify(gomock.Any(), "admins", ":warning: Не удалось обработать команду team1\n"+
+		"- checking time spends\n"+
+		"  - fetching member 'Ivan Ivanov' worked issues\n"+
+		"    - jira rejected search query (400)\n"+
+		"      - jira rejected search query").Return(nil)
+	n.EXPECT().Post(ctx, "channel-team2", "", gomock.Any()).Return("post1", nil)
+
+	err := app.Run(ctx)
+	require.ErrorIs(t, err, ErrPartialFailure)
+	require.ErrorIs(t, err, client.ErrBadJQL)
+}
+
+// teamResolver adds members to teams by team name, unknown teams fail
+type teamResolver map[string][]config.Member
+
+func (r teamResolver) ResolveTeam(_ context.Context, team config.Team) (config.Team, error) {
+	members, ok := r[team.Name]
+	if !ok {
+		return config.Team{}, errors.New("jira group not found")
+	}
+	team.Members = append(team.Members, members...)
+
+	return team, nil
+}
+
+func TestApp_RunDiscoveryFailureIsolated(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ctx := context.Background()
+	day := time.Date(2023, 9, 1, 0, 0, 0, 0, time.UTC)
+	ivan := config.Member{Name: "Ivan Ivanov", JiraAccID: "acc1", MattermostUsername: "ivanov.i"}
+	petr := config.Member{Name: "Petr Petrov", JiraAccID: "acc2", MattermostUsername: "petrov.p"}
+
+	dtf := mock_day_type_fetcher.NewMockDayTypeFetcher(ctrl)
+	wlf := mock_worklog_fetcher.NewMockWorkLogFetcher(ctrl)
+	n := mock_notifier.NewMockNotifier(ctrl)
+
+	app := NewCliApp(
+		config.Args{Date: day, Members: []string{"petrov.p"}},
+		config.Params{
+			Notifier: config.Notifier{Admin: config.Admin{Channel: "admins"}},
+			Teams: []config.Team{
+				{Name: "team1", Channel: "channel-team1"},
+				{Name: "team2", Channel: "channel-team2", Members: []config.Member{ivan}},
+				{Name: "team3", Channel: "channel-team3", Members: []config.Member{ivan}},
+			},
+		},
+		dtf,
+		wlf,
+		n,
+		nil,
+		teamResolver{"team2": {petr}, "team3": nil},
+		nil,
+		nil,
+	)
+
+	// the discovered member is selected, team3 has no selected members
+	dtf.EXPECT().FetchDayType(ctx, day).Return(model.WorkDay, nil)
+	wlf.EXPECT().UserWorkedIssuesByDate(ctx, model.User("acc2"), day, "").Return(nil, nil)
+	wlf.EXPECT().WorkLogsPerIssues(ctx, model.User("acc2"), day, day.Add(24*time.Hour-time.Second), nil).
+		Return(nil, nil)
+	n.EXPECT().Notify(gomock.Any(), "admins", ":warning: Не удалось обработать команду team1\n"+
+		"- resolving team 'team1' members\n"+
+		"  - jira group not found").Return(nil)
+	n.EXPECT().Post(ctx, "channel-team2", "", gomock.Any()).Return("post1", nil)
+
+	err := app.Run(ctx)
+	require.ErrorIs(t, err, ErrPartialFailure)
+	require.ErrorContains(t, err, "resolving team 'team1' members: jira group not found")
+
+	// member selectors are checked against discovered members
+	app.args.Members = []string{"sidorov.s"}
+	n.EXPECT().Notify(gomock.Any(), "admins", gomock.Any()).Return(nil)
+	require.ErrorIs(t, app.Run(ctx), config.ErrUnknownMember)
+}
+
+func TestApp_RunAdminFailureIsNotTeamFailure(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ctx := context.Background()
+	day := time.Date(2023, 9, 1, 0, 0, 0, 0, time.UTC)
+	broken := config.Member{Name: "Ivan Ivanov", JiraAccID: "bad-id"}
+	member := config.Member{Name: "Petr Petrov", JiraAccID: "acc2", MattermostUsername: "petrov.p"}
+
+	dtf := mock_day_type_fetcher.NewMockDayTypeFetcher(ctrl)
+	wlf := mock_worklog_fetcher.NewMockWorkLogFetcher(ctrl)
+	n := mock_notifier.NewMockNotifier(ctrl)
+
+	app := NewCliApp(
+		config.Args{Date: day},
+		config.Params{
+			Notifier: config.Notifier{Admin: config.Admin{Channel: "admins"}},
+			Teams: []config.Team{
+				{Name: "team1", Channel: "channel-team1", Members: []config.Member{broken}},
+				{Name: "team2", Channel: "channel-team2", Members: []config.Member{member}},
+			},
+		},
+		dtf,
+		wlf,
+		n,
+		nil,
+		nil,
+		nil,
+		nil,
+	)
+
+	dtf.EXPECT().FetchDayType(ctx, day).Return(model.WorkDay, nil).Times(2)
+	wlf.EXPECT().UserWorkedIssuesByDate(ctx, model.User("bad-id"), day, "").Return(nil, client.ErrBadJQL)
+	wlf.EXPECT().UserWorkedIssuesByDate(ctx, model.User("acc2"), day, "").Return(nil, nil)
+	wlf.EXPECT().WorkLogsPerIssues(ctx, model.User("acc2"), day, day.Add(24*time.Hour-time.Second), nil).
+		Return(nil, nil)
+	n.EXPECT().Notify(gomock.Any(), "admins", gomock.Any()).Return(errors.New("mattermost is down"))
+	n.EXPECT().Post(ctx, "channel-team2", "", gomock.Any()).Return("post1", nil)
+
+	// one of two teams failed, the failed alert does not count as a team failure
+	err := app.Run(ctx)
+	require.ErrorIs(t, err, ErrPartialFailure)
+	require.NotContains(t, err.Error(), "mattermost is down")
+}
+
+func TestApp_RunSummaryFailureIsNotTeamFailure(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ctx := context.Background()
+	day := time.Date(2023, 9, 1, 0, 0, 0, 0, time.UTC)
+	member := config.Member{Name: "Petr Petrov", JiraAccID: "acc2", MattermostUsername: "petrov.p"}
+
+	dtf := mock_day_type_fetcher.NewMockDayTypeFetcher(ctrl)
+	wlf := mock_worklog_fetcher.NewMockWorkLogFetcher(ctrl)
+	n := mock_notifier.NewMockNotifier(ctrl)
+
+	app := NewCliApp(
+		config.Args{Date: day},
+		config.Params{
+			Notifier: config.Notifier{Admin: config.Admin{Channel: "admins", Summary: true}},
+			Teams:    []config.Team{{Name: "team1", Channel: "channel-team1", Members: []config.Member{member}}},
+		},
+		dtf,
+		wlf,
+		n,
+		nil,
+		nil,
+		nil,
+		nil,
+	)
+
+	dtf.EXPECT().FetchDayType(ctx, day).Return(model.WorkDay, nil)
+	wlf.EXPECT().UserWorkedIssuesByDate(ctx, model.User("acc2"), day, "").Return(nil, nil)
+	wlf.EXPECT().WorkLogsPerIssues(ctx, model.User("acc2"), day, day.Add(24*time.Hour-time.Second), nil).
+		Return(nil, nil)
+	n.EXPECT().Post(ctx, "channel-team1", "", gomock.Any()).Return("post1", nil)
+	n.EXPECT().Notify(ctx, "admins", gomock.Any()).Return(errors.New("mattermost is down"))
+
+	require.NoError(t, app.Run(ctx))
+}
+
+func TestApp_RunWeekendIsNotFailure(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ctx := context.Background()
+	saturday := time.Date(2023, 9, 2, 0, 0, 0, 0, time.UTC)
+	dtf := mock_day_type_fetcher.NewMockDayTypeFetcher(ctrl)
+	n := mock_notifier.NewMockNotifier(ctrl)
+
+	app := NewCliApp(
+		config.Args{Date: saturday},
+		config.Params{
+			Teams: []config.Team{
+				{Name: "team1", Channel: "channel-team1"},
+				{
+					Name: "team2", Channel: "channel-team2",
+					Notify: config.NotifyPolicy{Policy: config.NotifyFridaySummary},
+				},
+			},
+		},
+		dtf,
+		nil,
+		n,
+		nil,
+		nil,
+		nil,
+		nil,
+	)
+
+	// the 'always' team has a day off, the friday summary team is skipped
+	dtf.EXPECT().FetchDayType(ctx, saturday).Return(model.NoWorkDay, nil)
+
+	require.NoError(t, app.Run(ctx))
+}
+
+func TestApp_RunStatsObservesErrors(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ctx := context.Background()
+	day := time.Date(2023, 9, 1, 0, 0, 0, 0, time.UTC)
+	dtf := mock_day_type_fetcher.NewMockDayTypeFetcher(ctrl)
+	m := metrics.New()
+
+	app := NewCliApp(
+		config.Args{Command: config.CmdStats, Date: day, From: day, To: day},
+		config.Params{Teams: []config.Team{{Name: "team1", Channel: "channel-team1"}}},
+		dtf,
+		nil,
+		nil,
+		nil,
+		nil,
+		m,
+		nil,
+	)
+
+	dtf.EXPECT().FetchDayType(ctx, day).Return(model.DayType(0), client.ErrServiceUnavailable).AnyTimes()
+	require.ErrorIs(t, app.RunStats(ctx), client.ErrServiceUnavailable)
+
+	rec := httptest.NewRecorder()
+	m.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	require.Contains(t, rec.Body.String(), `ts_notifier_errors_total{error="service_unavailable"} 1`)
+}
@@ -0,0 +1,171 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/duke0x/ts-notifier/config"
+	"github.com/duke0x/ts-notifier/model"
+	"github.com/duke0x/ts-notifier/tscalculator"
+)
+
+// Kinds of member notifications recorded in the state
+const (
+	reminderNotice = "reminder"
+	managerNotice  = "manager"
+)
+
+// maxDaysOff limits the search of the previous working day
+const maxDaysOff = 31
+
+// StateStore keeps the state between runs
+type StateStore interface {
+	// MissedDays records whether the member missed the day and returns
+	// the number of missed working days in a row ending with the day,
+	// prev is the working day before the day
+	MissedDays(team, member string, day, prev time.Time, missed bool) (int, error)
+	// PostID returns identifier of the team report post of the day,
+	// it is empty if nothing was posted for the day
+	PostID(team string, day time.Time) string
+	// SetPostID records identifier of the team report post of the day
+	SetPostID(team string, day time.Time, id string) error
+	// Notified reports whether the member notification of the kind was sent for the day
+	Notified(team, member, kind string, day time.Time) bool
+	// SetNotified records that the member notification of the kind was sent for the day
+	SetNotified(team, member, kind string, day time.Time) error
+}
+
+// escalate records members missed days and sends reminders by the team
+// escalation rules. Every reminder is sent once a day.
+func (app *App) escalate(
+	ctx context.Context,
+	day time.Time,
+	team config.Team,
+	trs tscalculator.TeamRemainSpends,
+) error {
+	prev, err := app.prevWorkingDay(ctx, day)
+	if err != nil {
+		return fmt.Errorf("finding previous working day: %w", err)
+	}
+
+	var errs []error
+	for _, mrs := range trs {
+		member := mrs.Member
+		days, err := app.store.MissedDays(team.Name, member.JiraAccID, day, prev, mrs.RemainSpend > 0)
+		if err != nil {
+			return fmt.Errorf("recording member '%s' missed days: %w", member.Name, err)
+		}
+
+		switch {
+		case days == 0:
+		case days < team.Escalation.ChannelDay():
+			errs = append(errs, app.remindMember(ctx, day, team, mrs))
+		case days >= team.Escalation.ManagerDay():
+			errs = append(errs, app.escalateToManager(ctx, day, team, mrs, days))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// remindMember sends the member a direct reminder unless it was sent for the day
+func (app *App) remindMember(
+	ctx context.Context,
+	day time.Time,
+	team config.Team,
+	mrs tscalculator.MemberRemainSpend,
+) error {
+	member := mrs.Member
+	if app.store.Notified(team.Name, member.JiraAccID, reminderNotice, day) {
+		return nil
+	}
+	if member.MattermostUsername == "" {
+		app.logger.WarnContext(ctx, "member has no mattermost username to remind", "team", team.Name, "member", member.Name)
+		return nil
+	}
+
+	err := app.notifier.NotifyUser(ctx, member.MattermostUsername,
+		"Напоминание: за "+day.Format("2006.01.02")+" нужно списать еще "+mrs.RemainSpend.String()+".")
+	if err != nil {
+		return fmt.Errorf("reminding member '%s': %w", member.Name, err)
+	}
+	app.logger.InfoContext(ctx, "member reminded directly", "team", team.Name, "member", member.Name)
+
+	if err := app.store.SetNotified(team.Name, member.JiraAccID, reminderNotice, day); err != nil {
+		return fmt.Errorf("recording member '%s' reminder: %w", member.Name, err)
+	}
+
+	return nil
+}
+
+// escalateToManager notifies the member manager or the team lead
+// unless it was notified for the day
+func (app *App) escalateToManager(
+	ctx context.Context,
+	day time.Time,
+	team config.Team,
+	mrs tscalculator.MemberRemainSpend,
+	days int,
+) error {
+	member := mrs.Member
+	if app.store.Notified(team.Name, member.JiraAccID, managerNotice, day) {
+		return nil
+	}
+	manager := member.Manager
+	if manager == "" {
+		manager = team.Lead
+	}
+	if manager == "" {
+		app.logger.WarnContext(ctx, "no manager to escalate to", "team", team.Name, "member", member.Name)
+		return nil
+	}
+
+	err := app.notifier.NotifyUser(ctx, manager,
+		member.Mention()+" не списывает время "+strconv.Itoa(days)+
+			" раб. дн. подряд, за "+day.Format("2006.01.02")+" не списано "+mrs.RemainSpend.String()+".")
+	if err != nil {
+		return fmt.Errorf("notify member '%s' manager: %w", member.Name, err)
+	}
+
+	if err := app.store.SetNotified(team.Name, member.JiraAccID, managerNotice, day); err != nil {
+		return fmt.Errorf("recording member '%s' manager notification: %w", member.Name, err)
+	}
+
+	return nil
+}
+
+// reminded returns members reminded directly about the day,
+// they are not mentioned in the team channel
+func (app *App) reminded(team config.Team, day time.Time, trs tscalculator.TeamRemainSpends) tscalculator.Reminded {
+	if !team.Escalation.Enabled || app.store == nil {
+		return nil
+	}
+
+	reminded := tscalculator.Reminded{}
+	for _, mrs := range trs {
+		if app.store.Notified(team.Name, mrs.Member.JiraAccID, reminderNotice, day) {
+			reminded[mrs.Member.JiraAccID] = true
+		}
+	}
+
+	return reminded
+}
+
+// prevWorkingDay returns the working day before the day
+func (app *App) prevWorkingDay(ctx context.Context, day time.Time) (time.Time, error) {
+	for i := 1; i <= maxDaysOff; i++ {
+		d := day.AddDate(0, 0, -i)
+		dt, err := app.dtFetcher.FetchDayType(ctx, d)
+		if err != nil {
+			return time.Time{}, fmt.Errorf("checking day '%s': %w", d.Format(model.DayFormat), err)
+		}
+		if dt != model.NoWorkDay {
+			return d, nil
+		}
+	}
+
+	return time.Time{}, fmt.Errorf("no working days in %d days before '%s'", maxDaysOff, day.Format(model.DayFormat))
+}
@@ -0,0 +1,138 @@
+package app
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/duke0x/ts-notifier/config"
+	mock_day_type_fetcher "github.com/duke0x/ts-notifier/mock/day_type_fetcher"
+	mock_notifier "github.com/duke0x/ts-notifier/mock/notifier"
+	mock_worklog_fetcher "github.com/duke0x/ts-notifier/mock/work_log_fetcher"
+	"github.com/duke0x/ts-notifier/model"
+	"github.com/duke0x/ts-notifier/tscalculator"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+)
+
+// missedDays is a state store returning preset missed days by member
+// and recording sent notifications
+type missedDays struct {
+	days     map[string]int
+	prev     time.Time
+	notified map[string]bool
+}
+
+func newMissedDays(days map[string]int) *missedDays {
+	return &missedDays{days: days, notified: map[string]bool{}}
+}
+
+func (md *missedDays) MissedDays(_, member string, _, prev time.Time, missed bool) (int, error) {
+	md.prev = prev
+	if !missed {
+		return 0, nil
+	}
+
+	return md.days[member], nil
+}
+
+func (md *missedDays) PostID(string, time.Time) string { return "" }
+
+func (md *missedDays) SetPostID(string, time.Time, string) error { return nil }
+
+func (md *missedDays) Notified(team, member, kind string, day time.Time) bool {
+	return md.notified[team+"/"+member+"/"+kind+"/"+day.Format("2006-01-02")]
+}
+
+func (md *missedDays) SetNotified(team, member, kind string, day time.Time) error {
+	md.notified[team+"/"+member+"/"+kind+"/"+day.Format("2006-01-02")] = true
+	return nil
+}
+
+func TestApp_escalate(t *testing.T) {
+	ctrl := gomock.NewController(t)
+
+	ctx := context.Background()
+	// monday, the previous working day is friday
+	day := time.Date(2023, 9, 4, 0, 0, 0, 0, time.UTC)
+	dtf := mock_day_type_fetcher.NewMockDayTypeFetcher(ctrl)
+	dtf.EXPECT().FetchDayType(ctx, day.AddDate(0, 0, -1)).Return(model.NoWorkDay, nil).Times(2)
+	dtf.EXPECT().FetchDayType(ctx, day.AddDate(0, 0, -2)).Return(model.NoWorkDay, nil).Times(2)
+	dtf.EXPECT().FetchDayType(ctx, day.AddDate(0, 0, -3)).Return(model.WorkDay, nil).Times(2)
+	n := mock_notifier.NewMockNotifier(ctrl)
+
+	team := config.Team{
+		Name:       "team1",
+		Lead:       "lead",
+		Escalation: config.Escalation{Enabled: true},
+	}
+	spend := func(id string, remain time.Duration) tscalculator.MemberRemainSpend {
+		return tscalculator.MemberRemainSpend{
+			Member:      config.Member{Name: id, JiraAccID: id, MattermostUsername: id + ".mm"},
+			RemainSpend: remain,
+		}
+	}
+	noUsername := spend("nousername", time.Hour)
+	noUsername.Member.MattermostUsername = ""
+	trs := tscalculator.TeamRemainSpends{
+		spend("done", 0),
+		spend("first", time.Hour),
+		spend("second", 2*time.Hour),
+		spend("third", 3*time.Hour),
+		noUsername,
+	}
+	store := newMissedDays(map[string]int{"first": 1, "second": 2, "third": 3, "nousername": 1})
+
+	n.EXPECT().NotifyUser(ctx, "first.mm", "Напоминание: за 2023.09.04 нужно списать еще 1h0m0s.").Return(nil)
+	n.EXPECT().NotifyUser(ctx, "lead", "@third.mm не списывает время 3 раб. дн. подряд, "+
+		"за 2023.09.04 не списано 3h0m0s.").Return(nil)
+
+	app := NewCliApp(config.Args{}, config.Params{}, dtf, nil, n, store, nil, nil, nil)
+	require.NoError(t, app.escalate(ctx, day, team, trs))
+	require.Equal(t, day.AddDate(0, 0, -3), store.prev)
+	require.Equal(t, tscalculator.Reminded{"first": true}, app.reminded(team, day, trs))
+
+	// reminders are sent once a day
+	require.NoError(t, app.escalate(ctx, day, team, trs))
+}
+
+func TestApp_RunEscalationReport(t *testing.T) {
+	ctrl := gomock.NewController(t)
+
+	ctx := context.Background()
+	day := time.Date(2023, 9, 1, 0, 0, 0, 0, time.UTC)
+	dtf := mock_day_type_fetcher.NewMockDayTypeFetcher(ctrl)
+	wlf := mock_worklog_fetcher.NewMockWorkLogFetcher(ctrl)
+	n := mock_notifier.NewMockNotifier(ctrl)
+
+	dtf.EXPECT().FetchDayType(ctx, gomock.Any()).Return(model.WorkDay, nil).AnyTimes()
+	wlf.EXPECT().UserWorkedIssuesByDate(ctx, gomock.Any(), day, "").Return(nil, nil).Times(2)
+	wlf.EXPECT().WorkLogsPerIssues(ctx, model.User("acc1"), day, gomock.Any(), nil).
+		Return([]model.WorkLog{{Key: "PRJ-1", User: "acc1", TimeSpentSeconds: 6 * 3600, Started: day}}, nil)
+	wlf.EXPECT().WorkLogsPerIssues(ctx, model.User("acc2"), day, gomock.Any(), nil).
+		Return([]model.WorkLog{{Key: "PRJ-2", User: "acc2", TimeSpentSeconds: 5 * 3600, Started: day}}, nil)
+
+	team := config.Team{
+		Name:       "team1",
+		Channel:    "channel-team1",
+		Escalation: config.Escalation{Enabled: true},
+		Members: []config.Member{
+			{Name: "Ivan Ivanov", JiraAccID: "acc1", MattermostUsername: "ivanov.i"},
+			{Name: "Petr Petrov", JiraAccID: "acc2", MattermostUsername: "petrov.p"},
+		},
+	}
+	store := newMissedDays(map[string]int{"acc1": 1, "acc2": 2})
+	app := NewCliApp(config.Args{Date: day}, config.Params{Teams: config.Teams{team}}, dtf, wlf, n, store, nil, nil, nil)
+
+	n.EXPECT().NotifyUser(ctx, "ivanov.i", "Напоминание: за 2023.09.01 нужно списать еще 2h0m0s.").Return(nil)
+	n.EXPECT().Post(ctx, "channel-team1", "", gomock.Any()).
+		DoAndReturn(func(_ context.Context, _, _ string, msg model.Message) (string, error) {
+			// the reminded member remaining time is reported without mention
+			require.Equal(t, "Отчет по списанию времени за 2023.09.01:\n"+
+				"  - Ivan Ivanov нужно списать еще 2h0m0s (напоминание отправлено лично).\n"+
+				"  - @petrov.p нужно списать еще 3h0m0s.\n", msg.Text)
+			return "post1", nil
+		})
+
+	require.NoError(t, app.Run(ctx))
+}
@@ -0,0 +1,52 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/duke0x/ts-notifier/config"
+	"github.com/duke0x/ts-notifier/model"
+)
+
+// followUpPost returns identifier of the team report post of the day
+// to follow up, it is empty if reports are sent as new posts
+func (app *App) followUpPost(team config.Team, day time.Time) string {
+	if !team.Notify.FollowsUp() || app.store == nil {
+		return ""
+	}
+
+	return app.store.PostID(team.Name, day)
+}
+
+// sendReport sends the team daily report. The first report of the day is
+// a new post, repeated ones update it or reply in its thread by the team
+// follow up setting.
+func (app *App) sendReport(ctx context.Context, day time.Time, team config.Team, report model.Message) error {
+	postID := app.followUpPost(team, day)
+	switch {
+	case !team.Notify.FollowsUp() || app.store == nil:
+		if _, err := app.notifier.Post(ctx, team.Channel, "", report); err != nil {
+			return err
+		}
+	case postID == "":
+		id, err := app.notifier.Post(ctx, team.Channel, "", report)
+		if err != nil {
+			return err
+		}
+		if err := app.store.SetPostID(team.Name, day, id); err != nil {
+			return fmt.Errorf("recording report post: %w", err)
+		}
+	case team.Notify.FollowUp == config.FollowUpEdit:
+		if err := app.notifier.UpdatePost(ctx, postID, report); err != nil {
+			return err
+		}
+	default:
+		if _, err := app.notifier.Post(ctx, team.Channel, postID, report); err != nil {
+			return err
+		}
+	}
+	app.sent.Add(1)
+
+	return nil
+}
@@ -0,0 +1,79 @@
+package app
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/duke0x/ts-notifier/config"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+)
+
+// posts is a state store keeping report posts by team and day
+type posts map[string]string
+
+func (p posts) MissedDays(string, string, time.Time, time.Time, bool) (int, error) { return 0, nil }
+
+func (p posts) Notified(string, string, string, time.Time) bool { return false }
+
+func (p posts) SetNotified(string, string, string, time.Time) error { return nil }
+
+func (p posts) PostID(team string, day time.Time) string {
+	return p[team+"/"+day.Format("2006-01-02")]
+}
+
+func (p posts) SetPostID(team string, day time.Time, id string) error {
+	p[team+"/"+day.Format("2006-01-02")] = id
+	return nil
+}
+
+func TestApp_RunFollowUp(t *testing.T) {
+	ctx := context.Background()
+	day := time.Date(2023, 8, 31, 0, 0, 0, 0, time.UTC)
+	report := gomock.Any()
+
+	t.Run("edit", func(t *testing.T) {
+		app, n := newPolicyApp(t, day, config.Team{
+			Notify: config.NotifyPolicy{FollowUp: config.FollowUpEdit},
+		})
+		store := posts{}
+		app.store = store
+
+		n.EXPECT().Post(ctx, "channel-team1", "", report).Return("post1", nil)
+		require.NoError(t, app.Run(ctx))
+		require.Equal(t, posts{"team1/2023-08-31": "post1"}, store)
+
+		n.EXPECT().UpdatePost(ctx, "post1", report).Return(nil)
+		require.NoError(t, app.Run(ctx))
+	})
+
+	t.Run("thread", func(t *testing.T) {
+		app, n := newPolicyApp(t, day, config.Team{
+			Notify: config.NotifyPolicy{FollowUp: config.FollowUpThread},
+		})
+		app.store = posts{"team1/2023-08-30": "post0", "team1/2023-08-31": "post1"}
+
+		n.EXPECT().Post(ctx, "channel-team1", "post1", report).Return("post2", nil)
+		require.NoError(t, app.Run(ctx))
+	})
+
+	t.Run("only when missing follows up earlier report", func(t *testing.T) {
+		app, n := newPolicyApp(t, day, config.Team{
+			Notify: config.NotifyPolicy{Policy: config.NotifyOnlyWhenMissing, FollowUp: config.FollowUpEdit},
+		})
+		app.store = posts{"team1/2023-08-31": "post1"}
+
+		n.EXPECT().UpdatePost(ctx, "post1", report).Return(nil)
+		require.NoError(t, app.Run(ctx))
+	})
+
+	t.Run("new posts without state", func(t *testing.T) {
+		app, n := newPolicyApp(t, day, config.Team{
+			Notify: config.NotifyPolicy{FollowUp: config.FollowUpEdit},
+		})
+
+		n.EXPECT().Post(ctx, "channel-team1", "", report).Return("post1", nil)
+		require.NoError(t, app.Run(ctx))
+	})
+}
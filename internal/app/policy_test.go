@@ -0,0 +1,84 @@
+package app
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/duke0x/ts-notifier/config"
+	mock_day_type_fetcher "github.com/duke0x/ts-notifier/mock/day_type_fetcher"
+	mock_notifier "github.com/duke0x/ts-notifier/mock/notifier"
+	mock_worklog_fetcher "github.com/duke0x/ts-notifier/mock/work_log_fetcher"
+	"github.com/duke0x/ts-notifier/model"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+)
+
+// newPolicyApp returns app checking the team with a member
+// who has logged the whole day on the day
+func newPolicyApp(t *testing.T, day time.Time, team config.Team) (*App, *mock_notifier.MockNotifier) {
+	ctrl := gomock.NewController(t)
+	dtf := mock_day_type_fetcher.NewMockDayTypeFetcher(ctrl)
+	wlf := mock_worklog_fetcher.NewMockWorkLogFetcher(ctrl)
+	n := mock_notifier.NewMockNotifier(ctrl)
+
+	ctx := context.Background()
+	dtf.EXPECT().FetchDayType(ctx, gomock.Any()).Return(model.WorkDay, nil).AnyTimes()
+	wlf.EXPECT().UserWorkedIssuesByDate(ctx, model.User("acc1"), gomock.Any(), "").Return(nil, nil).AnyTimes()
+	wlf.EXPECT().WorkLogsPerIssues(ctx, model.User("acc1"), gomock.Any(), gomock.Any(), nil).
+		DoAndReturn(func(_ context.Context, _ model.User, start, _ time.Time, _ []model.Issue) ([]model.WorkLog, error) {
+			return []model.WorkLog{{Key: "PRJ-1", User: "acc1", TimeSpentSeconds: 8 * 3600, Started: start}}, nil
+		}).AnyTimes()
+
+	team.Name, team.Channel = "team1", "channel-team1"
+	team.Members = []config.Member{{Name: "Ivan Ivanov", JiraAccID: "acc1", MattermostUsername: "ivanov.i"}}
+	app := NewCliApp(config.Args{Date: day}, config.Params{Teams: config.Teams{team}}, dtf, wlf, n, nil, nil, nil, nil)
+	app.now = func() time.Time { return day.Add(18 * time.Hour) }
+
+	return app, n
+}
+
+func TestApp_RunNotifyPolicy(t *testing.T) {
+	ctx := context.Background()
+	thursday := time.Date(2023, 8, 31, 0, 0, 0, 0, time.UTC)
+	friday := thursday.AddDate(0, 0, 1)
+
+	t.Run("always", func(t *testing.T) {
+		app, n := newPolicyApp(t, thursday, config.Team{})
+		n.EXPECT().Post(ctx, "channel-team1", "", gomock.Any()).Return("post1", nil)
+		require.NoError(t, app.Run(ctx))
+	})
+
+	t.Run("only when missing", func(t *testing.T) {
+		app, _ := newPolicyApp(t, thursday, config.Team{
+			Notify: config.NotifyPolicy{Policy: config.NotifyOnlyWhenMissing},
+		})
+		require.NoError(t, app.Run(ctx))
+	})
+
+	t.Run("friday summary on thursday", func(t *testing.T) {
+		app, _ := newPolicyApp(t, thursday, config.Team{
+			Notify: config.NotifyPolicy{Policy: config.NotifyFridaySummary},
+		})
+		require.NoError(t, app.Run(ctx))
+	})
+
+	t.Run("friday summary", func(t *testing.T) {
+		app, n := newPolicyApp(t, friday, config.Team{
+			Notify: config.NotifyPolicy{Policy: config.NotifyFridaySummary},
+		})
+		n.EXPECT().Notify(ctx, "channel-team1", "Статистика списания времени за 2023.08.28 - 2023.09.01:\n"+
+			"  1. @ivanov.i: списано в срок 5 из 5 дн. (100%), не списано 0s, "+
+			"среднее опоздание 0s, серия 5 дн. (лучшая 5 дн.).\n").Return(nil)
+		require.NoError(t, app.Run(ctx))
+	})
+
+	t.Run("quiet hours in team time zone", func(t *testing.T) {
+		// 18:00 UTC is 21:00 in Moscow
+		app, _ := newPolicyApp(t, thursday, config.Team{
+			Timezone: "Europe/Moscow",
+			Notify:   config.NotifyPolicy{QuietHours: config.QuietHours{From: "20:00", To: "09:00"}},
+		})
+		require.NoError(t, app.Run(ctx))
+	})
+}
@@ -0,0 +1,79 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/duke0x/ts-notifier/config"
+	"github.com/duke0x/ts-notifier/model"
+	"github.com/duke0x/ts-notifier/tscalculator"
+)
+
+const (
+	// recheckAction is an identifier of the report 'Recheck' button
+	recheckAction = "recheck"
+	// recheckLifetime is a time the 'Recheck' button works after the report is sent
+	recheckLifetime = 7 * 24 * time.Hour
+)
+
+// reportMessage returns the team daily report message, members reminded
+// directly are not mentioned. The 'Recheck' button is added if the API
+// public URL is set.
+func (app *App) reportMessage(day time.Time, team config.Team, trs tscalculator.TeamRemainSpends) model.Message {
+	reminded := app.reminded(team, day, trs)
+	msg := trs.Message(day, reminded)
+	if app.args.Detailed {
+		// attachments have no breakdown, so the whole text is sent with them
+		msg.Text, msg.Title = trs.DetailedReport(day, reminded), ""
+	}
+
+	srv := app.params.Server
+	url := srv.RecheckURL()
+	if url == "" {
+		return msg
+	}
+	now := app.now()
+	date := day.Format("2006-01-02")
+	expires := strconv.FormatInt(now.Add(recheckLifetime).Unix(), 10)
+	msg.Attachments = append(msg.Attachments, model.Attachment{
+		Text: "Проверено в " + now.In(team.Location()).Format("15:04"),
+		Actions: []model.Action{{
+			ID:   recheckAction,
+			Name: "Перепроверить",
+			URL:  url,
+			Context: map[string]string{
+				"team":      team.Name,
+				"date":      date,
+				"expires":   expires,
+				"signature": srv.RecheckSignature(team, date, expires),
+			},
+		}},
+	})
+
+	return msg
+}
+
+// RecheckTeam recalculates the team time spends for the day
+// and updates the team report post with them.
+func (app *App) RecheckTeam(ctx context.Context, day time.Time, team config.Team, postID string) error {
+	err := app.recheckTeam(ctx, day, team, postID)
+	app.metrics.ObserveError(err)
+
+	return err
+}
+
+func (app *App) recheckTeam(ctx context.Context, day time.Time, team config.Team, postID string) error {
+	teamSpends, err := app.calcTeam(ctx, day, team)
+	if err != nil {
+		return err
+	}
+
+	if err := app.notifier.UpdatePost(ctx, postID, app.reportMessage(day, team, teamSpends)); err != nil {
+		return fmt.Errorf("updating team '%s' report: %w", team.Name, err)
+	}
+	app.logger.InfoContext(ctx, "report rechecked", "team", team.Name, "post", postID)
+
+	return nil
+}
@@ -0,0 +1,63 @@
+package app
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/duke0x/ts-notifier/config"
+	"github.com/duke0x/ts-notifier/model"
+	"github.com/duke0x/ts-notifier/tscalculator"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestApp_RecheckTeam(t *testing.T) {
+	ctx := context.Background()
+	day := time.Date(2023, 8, 31, 0, 0, 0, 0, time.UTC)
+
+	app, n := newPolicyApp(t, day, config.Team{Timezone: "Europe/Moscow"})
+	app.params.Server = config.Server{AuthToken: "secret", PublicURL: "https://ts-notifier.myorg.com/"}
+	team := app.params.Teams[0]
+
+	var got model.Message
+	n.EXPECT().UpdatePost(ctx, "post1", gomock.Any()).DoAndReturn(
+		func(_ context.Context, _ string, msg model.Message) error {
+			got = msg
+			return nil
+		})
+	require.NoError(t, app.RecheckTeam(ctx, day, team, "post1"))
+
+	require.Len(t, got.Attachments, 2)
+	require.Equal(t, model.StatusOK, got.Attachments[0].Status)
+	// 18:00 UTC is 21:00 in Moscow
+	require.Equal(t, model.Attachment{
+		Text: "Проверено в 21:00",
+		Actions: []model.Action{{
+			ID:   "recheck",
+			Name: "Перепроверить",
+			URL:  "https://ts-notifier.myorg.com/actions/recheck",
+			// the button expires in a week after the check
+			Context: map[string]string{
+				"team":      "team1",
+				"date":      "2023-08-31",
+				"expires":   "1694109600",
+				"signature": app.params.Server.RecheckSignature(team, "2023-08-31", "1694109600"),
+			},
+		}},
+	}, got.Attachments[1])
+}
+
+func TestApp_reportMessageDetailed(t *testing.T) {
+	day := time.Date(2023, 8, 31, 0, 0, 0, 0, time.UTC)
+	app, _ := newPolicyApp(t, day, config.Team{})
+	app.args.Detailed = true
+	team := app.params.Teams[0]
+	trs := tscalculator.TeamRemainSpends{{Member: team.Members[0], RemainSpend: time.Hour}}
+
+	// the detailed text is sent with the attachments instead of the title
+	msg := app.reportMessage(day, team, trs)
+	require.Empty(t, msg.Title)
+	require.Equal(t, trs.DetailedReport(day, nil), msg.Text)
+	require.Len(t, msg.Attachments, 1)
+}
@@ -0,0 +1,73 @@
+package discovery
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/duke0x/ts-notifier/config"
+)
+
+// Cache keeps resolved teams and resolves them again when they get older
+// than the interval, so long-running services follow membership changes
+type Cache struct {
+	resolver *Resolver
+	teams    config.Teams
+	interval time.Duration
+	now      func() time.Time
+
+	mu       sync.Mutex
+	resolved map[string]config.Team
+	at       time.Time
+}
+
+// NewCache returns cache of the configured teams resolved with the resolver
+func NewCache(resolver *Resolver, teams config.Teams, interval time.Duration) *Cache {
+	return &Cache{
+		resolver: resolver,
+		teams:    teams,
+		interval: interval,
+		now:      time.Now,
+		resolved: make(map[string]config.Team, len(teams)),
+	}
+}
+
+// Teams returns resolved teams. A team failed to resolve is returned
+// as previously resolved, teams never resolved are left out and their
+// errors are returned joined. Teams are resolved again on the next call
+// until all of them succeed.
+func (c *Cache) Teams(ctx context.Context) (config.Teams, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var errs []error
+	stale := c.at.IsZero() || c.now().Sub(c.at) >= c.interval
+	if stale {
+		for _, t := range c.teams {
+			resolved, err := c.resolver.ResolveTeam(ctx, t)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("resolving team '%s' members: %w", t.Name, err))
+				continue
+			}
+			c.resolved[t.Name] = resolved
+		}
+		if len(errs) == 0 {
+			c.at = c.now()
+		}
+	}
+
+	teams := make(config.Teams, 0, len(c.teams))
+	for _, t := range c.teams {
+		if resolved, ok := c.resolved[t.Name]; ok {
+			teams = append(teams, resolved)
+		}
+	}
+	err := errors.Join(errs...)
+	if err != nil && len(teams) > 0 {
+		c.resolver.logger.WarnContext(ctx, "keeping previously discovered team members", "error", err)
+	}
+
+	return teams, err
+}
@@ -0,0 +1,52 @@
+package discovery
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/duke0x/ts-notifier/client"
+	"github.com/duke0x/ts-notifier/config"
+	"github.com/duke0x/ts-notifier/model"
+)
+
+func TestCache_Teams(t *testing.T) {
+	jira := fakeJira{groups: map[string][]model.Account{"backend": {ivanJira}}}
+	teams := config.Teams{{Name: "backend", Discovery: config.Discovery{JiraGroup: "backend"}}}
+
+	now := time.Date(2023, 9, 1, 10, 0, 0, 0, time.UTC)
+	c := NewCache(New(jira, nil, discard), teams, time.Hour)
+	c.now = func() time.Time { return now }
+
+	ctx := context.Background()
+	got, err := c.Teams(ctx)
+	require.NoError(t, err)
+	require.Len(t, got[0].Members, 1)
+
+	// membership changes are seen after the interval only
+	jira.groups["backend"] = []model.Account{ivanJira, petrJira}
+	got, err = c.Teams(ctx)
+	require.NoError(t, err)
+	require.Len(t, got[0].Members, 1)
+
+	now = now.Add(time.Hour)
+	got, err = c.Teams(ctx)
+	require.NoError(t, err)
+	require.Len(t, got[0].Members, 2)
+
+	// previously resolved teams are kept if resolving fails
+	teams[0].Discovery = config.Discovery{JiraProject: "PRJ", JiraRole: "Developers"}
+	now = now.Add(time.Hour)
+	got, err = c.Teams(ctx)
+	require.ErrorIs(t, err, client.ErrNotFound)
+	require.Len(t, got, 1)
+	require.Len(t, got[0].Members, 2)
+
+	// a team never resolved is left out, others are returned
+	c = NewCache(New(jira, nil, discard), append(config.Teams{{Name: "mobile"}}, teams...), time.Hour)
+	got, err = c.Teams(ctx)
+	require.ErrorIs(t, err, client.ErrNotFound)
+	require.Equal(t, config.Teams{{Name: "mobile"}}, got)
+}
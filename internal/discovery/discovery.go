@@ -0,0 +1,185 @@
+// Package discovery resolves team members from Jira groups,
+// project roles and Mattermost channels.
+package discovery
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"github.com/duke0x/ts-notifier/client"
+	"github.com/duke0x/ts-notifier/config"
+	"github.com/duke0x/ts-notifier/model"
+)
+
+// JiraDirectory fetches Jira users
+type JiraDirectory interface {
+	GroupMembers(ctx context.Context, group string) ([]model.Account, error)
+	ProjectRoleMembers(ctx context.Context, project, role string) ([]model.Account, error)
+	UserByEmail(ctx context.Context, email string) (model.Account, error)
+}
+
+// ChatDirectory fetches chat channel users
+type ChatDirectory interface {
+	ChannelMembers(ctx context.Context, channel string) ([]model.Account, error)
+}
+
+type Resolver struct {
+	jira   JiraDirectory
+	chat   ChatDirectory
+	logger *slog.Logger
+}
+
+// New returns team members resolver, chat can be nil
+// if no team discovers members in chat channels
+func New(jira JiraDirectory, chat ChatDirectory, logger *slog.Logger) *Resolver {
+	if logger == nil {
+		logger = slog.Default()
+	}
+
+	return &Resolver{jira: jira, chat: chat, logger: logger}
+}
+
+// ResolveTeams returns teams with discovered members added.
+// A team failed to resolve does not stop others, it is left out
+// of the returned teams and its error is returned joined.
+func (r *Resolver) ResolveTeams(ctx context.Context, teams config.Teams) (config.Teams, error) {
+	resolved := make(config.Teams, 0, len(teams))
+	var errs []error
+	for _, team := range teams {
+		t, err := r.ResolveTeam(ctx, team)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("resolving team '%s' members: %w", team.Name, err))
+			continue
+		}
+		resolved = append(resolved, t)
+	}
+
+	return resolved, errors.Join(errs...)
+}
+
+// ResolveTeam returns the team with discovered members added to the listed ones.
+// Listed members take precedence over discovered members with the same
+// Jira account ID or email.
+func (r *Resolver) ResolveTeam(ctx context.Context, team config.Team) (config.Team, error) {
+	d := team.Discovery
+	if !d.Enabled() {
+		return team, nil
+	}
+
+	var jiraAccs []model.Account
+	if d.JiraGroup != "" {
+		accs, err := r.jira.GroupMembers(ctx, d.JiraGroup)
+		if err != nil {
+			return config.Team{}, err
+		}
+		jiraAccs = append(jiraAccs, accs...)
+	}
+	if d.JiraProject != "" {
+		accs, err := r.jira.ProjectRoleMembers(ctx, d.JiraProject, d.JiraRole)
+		if err != nil {
+			return config.Team{}, err
+		}
+		jiraAccs = append(jiraAccs, accs...)
+	}
+
+	var chatAccs []model.Account
+	if d.MattermostChannel != "" {
+		if r.chat == nil {
+			return config.Team{}, errors.New("mattermost is not configured")
+		}
+		accs, err := r.chat.ChannelMembers(ctx, d.MattermostChannel)
+		if err != nil {
+			return config.Team{}, err
+		}
+		chatAccs = accs
+	}
+
+	// channel members are the team if no Jira source is set
+	if d.JiraGroup == "" && d.JiraProject == "" {
+		var err error
+		if jiraAccs, err = r.jiraAccounts(ctx, team.Name, chatAccs); err != nil {
+			return config.Team{}, err
+		}
+	}
+
+	chatByEmail := make(map[string]model.Account, len(chatAccs))
+	for _, acc := range chatAccs {
+		if acc.Email != "" {
+			chatByEmail[strings.ToLower(acc.Email)] = acc
+		}
+	}
+
+	members := make([]config.Member, 0, len(team.Members)+len(jiraAccs))
+	ids := make(map[string]bool, cap(members))
+	emails := make(map[string]bool, cap(members))
+	add := func(m config.Member) {
+		email := strings.ToLower(m.Email)
+		if ids[m.JiraAccID] || (email != "" && emails[email]) {
+			return
+		}
+		if m.MattermostUsername == "" {
+			m.MattermostUsername = chatByEmail[email].Username
+		}
+		ids[m.JiraAccID] = true
+		if email != "" {
+			emails[email] = true
+		}
+		members = append(members, m)
+	}
+
+	for _, m := range team.Members {
+		add(m)
+	}
+	listed := len(members)
+	for _, acc := range jiraAccs {
+		add(config.Member{Name: acc.Name, JiraAccID: acc.ID, Email: acc.Email})
+	}
+	for _, m := range members[listed:] {
+		if m.MattermostUsername == "" {
+			r.logger.WarnContext(ctx, "discovered member has no mattermost username, it is named in reports",
+				"team", team.Name, "member", m.Name, "email", m.Email)
+		}
+	}
+
+	r.logger.InfoContext(ctx, "team members discovered",
+		"team", team.Name, "listed", listed, "discovered", len(members)-listed)
+	team.Members = members
+
+	return team, nil
+}
+
+// jiraAccounts finds Jira accounts of the chat users by their emails,
+// users without Jira account are skipped
+func (r *Resolver) jiraAccounts(
+	ctx context.Context,
+	team string,
+	chatAccs []model.Account,
+) ([]model.Account, error) {
+	accs := make([]model.Account, 0, len(chatAccs))
+	for _, ca := range chatAccs {
+		if ca.Email == "" {
+			r.logger.WarnContext(ctx, "skipping channel member without email",
+				"team", team, "username", ca.Username)
+			continue
+		}
+
+		acc, err := r.jira.UserByEmail(ctx, ca.Email)
+		if errors.Is(err, client.ErrNotFound) {
+			r.logger.WarnContext(ctx, "skipping channel member without jira account",
+				"team", team, "username", ca.Username, "email", ca.Email)
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		if acc.Name == "" {
+			acc.Name = ca.Name
+		}
+		accs = append(accs, acc)
+	}
+
+	return accs, nil
+}
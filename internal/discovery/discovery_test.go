@@ -0,0 +1,134 @@
+package discovery
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/duke0x/ts-notifier/client"
+	"github.com/duke0x/ts-notifier/config"
+	"github.com/duke0x/ts-notifier/model"
+)
+
+type fakeJira struct {
+	groups map[string][]model.Account
+	roles  map[string][]model.Account
+	users  []model.Account
+}
+
+func (f fakeJira) GroupMembers(_ context.Context, group string) ([]model.Account, error) {
+	return f.groups[group], nil
+}
+
+func (f fakeJira) ProjectRoleMembers(_ context.Context, project, role string) ([]model.Account, error) {
+	accs, ok := f.roles[project+"/"+role]
+	if !ok {
+		return nil, fmt.Errorf("%w: no role", client.ErrNotFound)
+	}
+	return accs, nil
+}
+
+func (f fakeJira) UserByEmail(_ context.Context, email string) (model.Account, error) {
+	for _, u := range f.users {
+		if strings.EqualFold(u.Email, email) {
+			return u, nil
+		}
+	}
+	return model.Account{}, client.ErrNotFound
+}
+
+type fakeChat map[string][]model.Account
+
+func (f fakeChat) ChannelMembers(_ context.Context, channel string) ([]model.Account, error) {
+	return f[channel], nil
+}
+
+var discard = slog.New(slog.NewTextHandler(io.Discard, nil))
+
+var (
+	ivanJira = model.Account{ID: "acc1", Name: "Ivan Ivanov", Email: "ivanov@myorg.com"}
+	petrJira = model.Account{ID: "acc2", Name: "Petr Petrov", Email: "petrov@myorg.com"}
+	ivanChat = model.Account{ID: "u1", Name: "Ivan", Username: "ivanov.i", Email: "Ivanov@myorg.com"}
+	annaChat = model.Account{ID: "u3", Name: "Anna", Username: "anna", Email: "anna@myorg.com"}
+)
+
+func TestResolver_ResolveTeam(t *testing.T) {
+	jira := fakeJira{
+		groups: map[string][]model.Account{"backend-devs": {ivanJira, petrJira}},
+		roles:  map[string][]model.Account{"PRJ/Developers": {petrJira}},
+		users:  []model.Account{ivanJira},
+	}
+	chat := fakeChat{"ch1": {ivanChat, annaChat}}
+
+	tests := []struct {
+		name    string
+		team    config.Team
+		want    []config.Member
+		wantErr error
+	}{
+		{
+			name: "no discovery",
+			team: config.Team{Members: []config.Member{{Name: "Petr", JiraAccID: "acc2"}}},
+			want: []config.Member{{Name: "Petr", JiraAccID: "acc2"}},
+		},
+		{
+			name: "jira group matched with channel by email",
+			team: config.Team{Discovery: config.Discovery{JiraGroup: "backend-devs", MattermostChannel: "ch1"}},
+			want: []config.Member{
+				{Name: "Ivan Ivanov", JiraAccID: "acc1", MattermostUsername: "ivanov.i", Email: "ivanov@myorg.com"},
+				{Name: "Petr Petrov", JiraAccID: "acc2", Email: "petrov@myorg.com"},
+			},
+		},
+		{
+			name: "listed members take precedence",
+			team: config.Team{
+				Members:   []config.Member{{Name: "Petr", JiraAccID: "acc2", MattermostUsername: "petr"}},
+				Discovery: config.Discovery{JiraProject: "PRJ", JiraRole: "Developers", JiraGroup: "backend-devs"},
+			},
+			want: []config.Member{
+				{Name: "Petr", JiraAccID: "acc2", MattermostUsername: "petr"},
+				{Name: "Ivan Ivanov", JiraAccID: "acc1", Email: "ivanov@myorg.com"},
+			},
+		},
+		{
+			name: "channel members searched in jira",
+			team: config.Team{Discovery: config.Discovery{MattermostChannel: "ch1"}},
+			want: []config.Member{
+				{Name: "Ivan Ivanov", JiraAccID: "acc1", MattermostUsername: "ivanov.i", Email: "ivanov@myorg.com"},
+			},
+		},
+		{
+			name:    "unknown role",
+			team:    config.Team{Discovery: config.Discovery{JiraProject: "PRJ", JiraRole: "Testers"}},
+			wantErr: client.ErrNotFound,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := New(jira, chat, discard).ResolveTeam(context.Background(), tt.team)
+			if tt.wantErr != nil {
+				require.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.want, got.Members)
+		})
+	}
+}
+
+func TestResolver_NoChat(t *testing.T) {
+	got, err := New(fakeJira{}, nil, discard).ResolveTeams(context.Background(), config.Teams{{
+		Name:      "backend",
+		Discovery: config.Discovery{MattermostChannel: "ch1"},
+	}, {
+		Name: "mobile",
+	}})
+	require.ErrorContains(t, err, "resolving team 'backend' members: mattermost is not configured")
+	// the failed team does not stop others
+	require.Equal(t, config.Teams{{Name: "mobile"}}, got)
+}
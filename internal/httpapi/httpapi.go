@@ -0,0 +1,454 @@
+// Package httpapi implements HTTP API server exposing time spends
+// reports and on demand team notifications.
+package httpapi
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/duke0x/ts-notifier/config"
+	"github.com/duke0x/ts-notifier/tscalculator"
+)
+
+const (
+	dayFormat       = "2006-01-02"
+	defaultListen   = ":8080"
+	readHdrTimeout  = 10 * time.Second
+	shutdownTimeout = 10 * time.Second
+	// maxBodySize limits request bodies, e.g. Mattermost action requests
+	maxBodySize = 64 << 10
+	// maxPeriodDays limits member spends period, every day costs
+	// a day type request and Jira searches per member
+	maxPeriodDays = 92
+)
+
+var ErrNoAuthToken = errors.New("server auth token is not set")
+
+// TeamNotifier checks team time spends for the day and notifies the team
+type TeamNotifier interface {
+	RunTeam(ctx context.Context, day time.Time, team config.Team) error
+	// RecheckTeam updates the team report post with recalculated time spends
+	RecheckTeam(ctx context.Context, day time.Time, team config.Team, postID string) error
+}
+
+// SpendsObserver records calculated member remaining time, e.g. in metrics
+type SpendsObserver interface {
+	SetRemainSpend(team, jiraAccID string, remain time.Duration)
+}
+
+// TeamsSource returns served teams, e.g. with members discovered
+// in Jira and Mattermost on demand. Teams resolved successfully
+// are returned along with the error of the failed ones.
+type TeamsSource interface {
+	Teams(ctx context.Context) (config.Teams, error)
+}
+
+// StaticTeams are teams served as configured
+type StaticTeams config.Teams
+
+func (t StaticTeams) Teams(context.Context) (config.Teams, error) {
+	return config.Teams(t), nil
+}
+
+type Server struct {
+	config   config.Server
+	teams    TeamsSource
+	tsc      *tscalculator.TSCalc
+	notifier TeamNotifier
+	observer SpendsObserver
+	metrics  http.Handler
+}
+
+// New returns API server. Team reports are recorded by the observer,
+// it can be nil. Metrics handler is served on '/metrics'
+// without authorization, nil handler disables it.
+func New(
+	cfg config.Server,
+	teams TeamsSource,
+	tsc *tscalculator.TSCalc,
+	notifier TeamNotifier,
+	observer SpendsObserver,
+	metrics http.Handler,
+) *Server {
+	return &Server{
+		config:   cfg,
+		teams:    teams,
+		tsc:      tsc,
+		notifier: notifier,
+		observer: observer,
+		metrics:  metrics,
+	}
+}
+
+// ListenAndServe serves API requests on the configured address
+// until the context is done, then shuts the server down gracefully.
+// It refuses to start without auth token.
+func (s *Server) ListenAndServe(ctx context.Context) error {
+	if s.config.AuthToken == "" {
+		return ErrNoAuthToken
+	}
+
+	addr := s.config.Listen
+	if addr == "" {
+		addr = defaultListen
+	}
+
+	srv := &http.Server{
+		Addr:              addr,
+		Handler:           s.Handler(),
+		ReadHeaderTimeout: readHdrTimeout,
+	}
+
+	errc := make(chan error, 1)
+	go func() { errc <- srv.ListenAndServe() }()
+
+	select {
+	case err := <-errc:
+		return err
+	case <-ctx.Done():
+	}
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+
+	return srv.Shutdown(shutdownCtx)
+}
+
+// Handler returns API requests handler:
+//
+//	GET  /teams/{name}/report?date=YYYY-MM-DD
+//	POST /teams/{name}/notify?date=YYYY-MM-DD
+//	GET  /members/{jira account id}/spends?from=YYYY-MM-DD&to=YYYY-MM-DD
+//	POST /actions/recheck
+//	GET  /metrics
+func (s *Server) Handler() http.Handler {
+	api := http.NewServeMux()
+	api.HandleFunc("/teams/", s.handleTeams)
+	api.HandleFunc("/members/", s.handleMembers)
+
+	mux := http.NewServeMux()
+	mux.Handle("/", s.authorize(api))
+	// Mattermost can not send the token, the button context is signed instead
+	mux.HandleFunc(config.RecheckPath, s.handleRecheck)
+	if s.metrics != nil {
+		mux.Handle("/metrics", s.metrics)
+	}
+
+	return limitBody(mux)
+}
+
+// limitBody rejects request bodies larger than maxBodySize
+func limitBody(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		r.Body = http.MaxBytesReader(w, r.Body, maxBodySize)
+		next.ServeHTTP(w, r)
+	})
+}
+
+// authorize rejects requests without valid bearer token
+func (s *Server) authorize(next http.Handler) http.Handler {
+	want := []byte("Bearer " + s.config.AuthToken)
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got := []byte(r.Header.Get("Authorization"))
+		if s.config.AuthToken == "" || subtle.ConstantTimeCompare(got, want) != 1 {
+			writeError(w, http.StatusUnauthorized, errors.New("unauthorized"))
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+func (s *Server) handleTeams(w http.ResponseWriter, r *http.Request) {
+	name, action, ok := splitPath(r.URL.Path, "/teams/")
+	if !ok {
+		writeError(w, http.StatusNotFound, errors.New("not found"))
+		return
+	}
+
+	team, ok, err := s.team(r.Context(), name)
+	if err != nil {
+		writeError(w, http.StatusBadGateway, err)
+		return
+	}
+	if !ok {
+		writeError(w, http.StatusNotFound, fmt.Errorf("team '%s' not found", name))
+		return
+	}
+
+	day, err := parseDay(r.URL.Query().Get("date"), today())
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	switch {
+	case action == "report" && r.Method == http.MethodGet:
+		s.teamReport(r.Context(), w, day, team)
+	case action == "notify" && r.Method == http.MethodPost:
+		s.teamNotify(r.Context(), w, day, team)
+	case action == "report" || action == "notify":
+		writeError(w, http.StatusMethodNotAllowed, errors.New("method not allowed"))
+	default:
+		writeError(w, http.StatusNotFound, errors.New("not found"))
+	}
+}
+
+func (s *Server) teamReport(ctx context.Context, w http.ResponseWriter, day time.Time, team config.Team) {
+	rep := teamReport{
+		Team:       team.Name,
+		Date:       day.Format(dayFormat),
+		WorkingDay: true,
+		Members:    []memberSpend{},
+	}
+
+	trs, err := s.tsc.CalcDailyTimeSpends(ctx, day, team)
+	if errors.Is(err, tscalculator.ErrNonWorkingDay) {
+		rep.WorkingDay = false
+		writeJSON(w, http.StatusOK, rep)
+		return
+	}
+	if err != nil {
+		writeError(w, http.StatusBadGateway, err)
+		return
+	}
+
+	rep.RemainSpendSeconds = int64(trs.RemainSpend().Seconds())
+	for _, mrs := range trs {
+		rep.Members = append(rep.Members, newMemberSpend(mrs))
+		if s.observer != nil {
+			s.observer.SetRemainSpend(team.Name, mrs.Member.JiraAccID, mrs.RemainSpend)
+		}
+	}
+
+	writeJSON(w, http.StatusOK, rep)
+}
+
+func (s *Server) teamNotify(ctx context.Context, w http.ResponseWriter, day time.Time, team config.Team) {
+	if err := s.notifier.RunTeam(ctx, day, team); err != nil {
+		writeError(w, http.StatusBadGateway, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, notifyResult{
+		Team:   team.Name,
+		Date:   day.Format(dayFormat),
+		Status: "sent",
+	})
+}
+
+func (s *Server) handleMembers(w http.ResponseWriter, r *http.Request) {
+	id, action, ok := splitPath(r.URL.Path, "/members/")
+	if !ok || action != "spends" {
+		writeError(w, http.StatusNotFound, errors.New("not found"))
+		return
+	}
+	if r.Method != http.MethodGet {
+		writeError(w, http.StatusMethodNotAllowed, errors.New("method not allowed"))
+		return
+	}
+
+	team, member, ok, err := s.member(r.Context(), id)
+	if err != nil {
+		writeError(w, http.StatusBadGateway, err)
+		return
+	}
+	if !ok {
+		writeError(w, http.StatusNotFound, fmt.Errorf("member '%s' not found", id))
+		return
+	}
+
+	qp := r.URL.Query()
+	to, err := parseDay(qp.Get("to"), today())
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	from, err := parseDay(qp.Get("from"), to)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	if from.After(to) {
+		writeError(w, http.StatusBadRequest, config.ErrBadPeriod)
+		return
+	}
+	if from.AddDate(0, 0, maxPeriodDays).Before(to.AddDate(0, 0, 1)) {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("period is longer than %d days", maxPeriodDays))
+		return
+	}
+
+	spends, err := s.tsc.CalcMemberSpends(r.Context(), from, to, team, member)
+	if err != nil {
+		writeError(w, http.StatusBadGateway, err)
+		return
+	}
+
+	rep := memberSpends{
+		Member: newMember(member),
+		From:   from.Format(dayFormat),
+		To:     to.Format(dayFormat),
+		Days:   []daySpend{},
+	}
+	for _, drs := range spends {
+		ms := newMemberSpend(drs.MemberRemainSpend)
+		rep.Days = append(rep.Days, daySpend{
+			Date:               drs.Day.Format(dayFormat),
+			LoggedSeconds:      ms.LoggedSeconds,
+			RemainSpendSeconds: ms.RemainSpendSeconds,
+		})
+	}
+
+	writeJSON(w, http.StatusOK, rep)
+}
+
+// handleRecheck updates the team report post when its 'Recheck' button is pressed
+func (s *Server) handleRecheck(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeError(w, http.StatusMethodNotAllowed, errors.New("method not allowed"))
+		return
+	}
+
+	var req actionRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		code := http.StatusBadRequest
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			code = http.StatusRequestEntityTooLarge
+		}
+		writeError(w, code, fmt.Errorf("decoding request: %w", err))
+		return
+	}
+
+	// the signature covers the team channel, so the button works in it only.
+	// It is checked before the team lookup not to reveal team names.
+	name, date, expires := req.Context["team"], req.Context["date"], req.Context["expires"]
+	signed := config.Team{Name: name, Channel: req.ChannelID}
+	sign := []byte(s.config.RecheckSignature(signed, date, expires))
+	if s.config.AuthToken == "" || !hmac.Equal([]byte(req.Context["signature"]), sign) {
+		writeError(w, http.StatusUnauthorized, errors.New("unauthorized"))
+		return
+	}
+	exp, err := strconv.ParseInt(expires, 10, 64)
+	if err != nil || time.Now().Unix() > exp {
+		writeError(w, http.StatusUnauthorized, errors.New("recheck button expired"))
+		return
+	}
+	if req.PostID == "" {
+		writeError(w, http.StatusBadRequest, errors.New("post_id is required"))
+		return
+	}
+
+	team, ok, err := s.team(r.Context(), name)
+	if err != nil {
+		writeError(w, http.StatusBadGateway, err)
+		return
+	}
+	if !ok {
+		writeError(w, http.StatusNotFound, fmt.Errorf("team '%s' not found", name))
+		return
+	}
+	// the team channel can change after the report is sent
+	if req.ChannelID != team.Channel {
+		writeError(w, http.StatusForbidden, errors.New("post is not in the team channel"))
+		return
+	}
+
+	day, err := parseDay(date, today())
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	if err := s.notifier.RecheckTeam(r.Context(), day, team, req.PostID); err != nil {
+		writeError(w, http.StatusBadGateway, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, actionResponse{
+		EphemeralText: "Отчет команды " + team.Name + " за " + day.Format(dayFormat) + " обновлен",
+	})
+}
+
+// team returns team by its name. Teams source error is returned
+// only if the team is not found, other teams can fail to resolve.
+func (s *Server) team(ctx context.Context, name string) (config.Team, bool, error) {
+	teams, err := s.teams.Teams(ctx)
+	for _, team := range teams {
+		if team.Name == name {
+			return team, true, nil
+		}
+	}
+	if err != nil {
+		return config.Team{}, false, fmt.Errorf("resolving teams: %w", err)
+	}
+
+	return config.Team{}, false, nil
+}
+
+// member returns member of any team and the team by Jira account identifier.
+// Teams source error is returned only if the member is not found.
+func (s *Server) member(ctx context.Context, jiraAccID string) (config.Team, config.Member, bool, error) {
+	teams, err := s.teams.Teams(ctx)
+	for _, team := range teams {
+		for _, member := range team.Members {
+			if member.JiraAccID == jiraAccID {
+				return team, member, true, nil
+			}
+		}
+	}
+	if err != nil {
+		return config.Team{}, config.Member{}, false, fmt.Errorf("resolving teams: %w", err)
+	}
+
+	return config.Team{}, config.Member{}, false, nil
+}
+
+// splitPath splits '<prefix><id>/<action>' path into id and action
+func splitPath(path, prefix string) (id, action string, ok bool) {
+	parts := strings.Split(strings.TrimPrefix(path, prefix), "/")
+	if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+		return "", "", false
+	}
+
+	return parts[0], parts[1], true
+}
+
+// parseDay parses day in YYYY-MM-DD format, empty day is replaced with def
+func parseDay(day string, def time.Time) (time.Time, error) {
+	if day == "" {
+		return def, nil
+	}
+
+	t, err := time.Parse(dayFormat, day)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("%w '%s', try YYYY-MM-DD", config.ErrBadDayFormat, day)
+	}
+
+	return t, nil
+}
+
+func today() time.Time {
+	now := time.Now().UTC()
+
+	return time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+func writeJSON(w http.ResponseWriter, code int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+func writeError(w http.ResponseWriter, code int, err error) {
+	writeJSON(w, code, errorResponse{Error: err.Error()})
+}
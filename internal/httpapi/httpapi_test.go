@@ -0,0 +1,354 @@
+package httpapi
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/duke0x/ts-notifier/config"
+	mock_day_type_fetcher "github.com/duke0x/ts-notifier/mock/day_type_fetcher"
+	mock_worklog_fetcher "github.com/duke0x/ts-notifier/mock/work_log_fetcher"
+	"github.com/duke0x/ts-notifier/model"
+	"github.com/duke0x/ts-notifier/tscalculator"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+)
+
+const token = "secret"
+
+type teamNotifierFunc func(day time.Time, team config.Team) error
+
+func (f teamNotifierFunc) RunTeam(_ context.Context, day time.Time, team config.Team) error {
+	return f(day, team)
+}
+
+func (f teamNotifierFunc) RecheckTeam(context.Context, time.Time, config.Team, string) error {
+	return errors.New("unexpected recheck")
+}
+
+// recheckFunc is a team notifier rechecking reports only
+type recheckFunc func(day time.Time, team config.Team, postID string) error
+
+func (f recheckFunc) RunTeam(context.Context, time.Time, config.Team) error {
+	return errors.New("unexpected run")
+}
+
+func (f recheckFunc) RecheckTeam(_ context.Context, day time.Time, team config.Team, postID string) error {
+	return f(day, team, postID)
+}
+
+// remainSpends records member remaining time by team and member
+type remainSpends map[string]time.Duration
+
+func (rs remainSpends) SetRemainSpend(team, jiraAccID string, remain time.Duration) {
+	rs[team+"/"+jiraAccID] = remain
+}
+
+var testTeams = config.Teams{{
+	Name:    "backend",
+	Channel: "ch1",
+	Members: []config.Member{{
+		Name:               "Ivan Ivanov",
+		JiraAccID:          "acc1",
+		MattermostUsername: "ivanov.i",
+	}},
+}}
+
+func newTestServer(t *testing.T, day time.Time, dayType model.DayType, tn TeamNotifier) *Server {
+	ctrl := gomock.NewController(t)
+	dc := mock_day_type_fetcher.NewMockDayTypeFetcher(ctrl)
+	wlf := mock_worklog_fetcher.NewMockWorkLogFetcher(ctrl)
+
+	ctx := context.Background()
+	dc.EXPECT().FetchDayType(ctx, day).Return(dayType, nil).AnyTimes()
+
+	issues := []model.Issue{{ID: "1", Key: "PRJ-1"}}
+	wlf.EXPECT().UserWorkedIssuesByDate(ctx, model.User("acc1"), day, "").Return(issues, nil).AnyTimes()
+	wlf.EXPECT().WorkLogsPerIssues(
+		ctx, model.User("acc1"), day, day.Add(time.Hour*23+time.Minute*59+time.Second*59), issues,
+	).Return([]model.WorkLog{{
+		Key:              "PRJ-1",
+		User:             "acc1",
+		TimeSpentSeconds: 6 * 3600,
+		Started:          day.Add(10 * time.Hour),
+	}}, nil).AnyTimes()
+
+	return New(
+		config.Server{AuthToken: token},
+		StaticTeams(testTeams),
+		tscalculator.New(dc, wlf, nil),
+		tn,
+		nil,
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			_, _ = w.Write([]byte("metrics"))
+		}),
+	)
+}
+
+func serve(s *Server, method, target, auth string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, target, nil)
+	if auth != "" {
+		req.Header.Set("Authorization", auth)
+	}
+	rec := httptest.NewRecorder()
+	s.Handler().ServeHTTP(rec, req)
+
+	return rec
+}
+
+func TestServer_TeamReport(t *testing.T) {
+	day := time.Date(2023, 9, 1, 0, 0, 0, 0, time.UTC)
+	s := newTestServer(t, day, model.WorkDay, nil)
+	observed := remainSpends{}
+	s.observer = observed
+
+	rec := serve(s, http.MethodGet, "/teams/backend/report?date=2023-09-01", "Bearer "+token)
+	require.Equal(t, http.StatusOK, rec.Code)
+	require.Equal(t, remainSpends{"backend/acc1": 2 * time.Hour}, observed)
+
+	var got teamReport
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &got))
+	require.Equal(t, teamReport{
+		Team:               "backend",
+		Date:               "2023-09-01",
+		WorkingDay:         true,
+		RemainSpendSeconds: 2 * 3600,
+		Members: []memberSpend{{
+			member: member{
+				Name:               "Ivan Ivanov",
+				JiraAccID:          "acc1",
+				MattermostUsername: "ivanov.i",
+			},
+			LoggedSeconds:      6 * 3600,
+			RemainSpendSeconds: 2 * 3600,
+		}},
+	}, got)
+}
+
+func TestServer_MemberSpends(t *testing.T) {
+	day := time.Date(2023, 9, 1, 0, 0, 0, 0, time.UTC)
+	s := newTestServer(t, day, model.WorkDay, nil)
+
+	rec := serve(s, http.MethodGet, "/members/acc1/spends?from=2023-09-01&to=2023-09-01", "Bearer "+token)
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	var got memberSpends
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &got))
+	require.Equal(t, []daySpend{{
+		Date:               "2023-09-01",
+		LoggedSeconds:      6 * 3600,
+		RemainSpendSeconds: 2 * 3600,
+	}}, got.Days)
+}
+
+func TestServer_TeamNotify(t *testing.T) {
+	day := time.Date(2023, 9, 1, 0, 0, 0, 0, time.UTC)
+
+	var notified string
+	s := newTestServer(t, day, model.WorkDay, teamNotifierFunc(func(d time.Time, team config.Team) error {
+		require.Equal(t, day, d)
+		notified = team.Name
+		return nil
+	}))
+
+	rec := serve(s, http.MethodPost, "/teams/backend/notify?date=2023-09-01", "Bearer "+token)
+	require.Equal(t, http.StatusOK, rec.Code)
+	require.Equal(t, "backend", notified)
+
+	s = newTestServer(t, day, model.WorkDay, teamNotifierFunc(func(time.Time, config.Team) error {
+		return errors.New("mattermost is down")
+	}))
+	rec = serve(s, http.MethodPost, "/teams/backend/notify?date=2023-09-01", "Bearer "+token)
+	require.Equal(t, http.StatusBadGateway, rec.Code)
+	require.JSONEq(t, `{"error": "mattermost is down"}`, rec.Body.String())
+}
+
+func TestServer_Errors(t *testing.T) {
+	day := time.Date(2023, 9, 1, 0, 0, 0, 0, time.UTC)
+	s := newTestServer(t, day, model.WorkDay, nil)
+
+	tests := []struct {
+		name   string
+		method string
+		target string
+		auth   string
+		want   int
+	}{
+		{"no token", http.MethodGet, "/teams/backend/report", "", http.StatusUnauthorized},
+		{"bad token", http.MethodGet, "/teams/backend/report", "Bearer nope", http.StatusUnauthorized},
+		{"unknown team", http.MethodGet, "/teams/frontend/report", "Bearer " + token, http.StatusNotFound},
+		{"unknown action", http.MethodGet, "/teams/backend/stats", "Bearer " + token, http.StatusNotFound},
+		{"bad method", http.MethodGet, "/teams/backend/notify", "Bearer " + token, http.StatusMethodNotAllowed},
+		{"bad date", http.MethodGet, "/teams/backend/report?date=01.09.2023", "Bearer " + token, http.StatusBadRequest},
+		{"unknown member", http.MethodGet, "/members/acc2/spends", "Bearer " + token, http.StatusNotFound},
+		{"bad period", http.MethodGet, "/members/acc1/spends?from=2023-09-02&to=2023-09-01", "Bearer " + token, http.StatusBadRequest},
+		{"long period", http.MethodGet, "/members/acc1/spends?from=2023-06-01&to=2023-09-01", "Bearer " + token, http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := serve(s, tt.method, tt.target, tt.auth)
+			require.Equal(t, tt.want, rec.Code)
+		})
+	}
+}
+
+func TestServer_Metrics(t *testing.T) {
+	s := newTestServer(t, time.Now(), model.WorkDay, nil)
+
+	rec := serve(s, http.MethodGet, "/metrics", "")
+	require.Equal(t, http.StatusOK, rec.Code)
+	require.Equal(t, "metrics", rec.Body.String())
+}
+
+func TestServer_ListenAndServeNoToken(t *testing.T) {
+	s := New(config.Server{}, nil, nil, nil, nil, nil)
+	require.ErrorIs(t, s.ListenAndServe(context.Background()), ErrNoAuthToken)
+}
+
+func TestServer_ListenAndServeShutdown(t *testing.T) {
+	s := New(config.Server{Listen: "127.0.0.1:0", AuthToken: token}, nil, nil, nil, nil, nil)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	require.NoError(t, s.ListenAndServe(ctx))
+}
+
+func TestServer_Recheck(t *testing.T) {
+	day := time.Date(2023, 9, 1, 0, 0, 0, 0, time.UTC)
+	var rechecked []string
+	s := newTestServer(t, day, model.WorkDay, recheckFunc(func(d time.Time, team config.Team, postID string) error {
+		require.Equal(t, day, d)
+		rechecked = append(rechecked, team.Name+"/"+postID)
+		return nil
+	}))
+
+	recheck := func(postID, channel string, context map[string]string) *httptest.ResponseRecorder {
+		body, _ := json.Marshal(actionRequest{PostID: postID, ChannelID: channel, Context: context})
+		req := httptest.NewRequest(http.MethodPost, "/actions/recheck", bytes.NewReader(body))
+		rec := httptest.NewRecorder()
+		s.Handler().ServeHTTP(rec, req)
+		return rec
+	}
+	srv := config.Server{AuthToken: token}
+	expires := strconv.FormatInt(time.Now().Add(time.Hour).Unix(), 10)
+	signed := func(team config.Team, date, expires string) map[string]string {
+		return map[string]string{
+			"team": team.Name, "date": date, "expires": expires,
+			"signature": srv.RecheckSignature(team, date, expires),
+		}
+	}
+	backend := testTeams[0]
+
+	rec := recheck("post1", "ch1", signed(backend, "2023-09-01", expires))
+	require.Equal(t, http.StatusOK, rec.Code)
+	require.JSONEq(t, `{"ephemeral_text":"Отчет команды backend за 2023-09-01 обновлен"}`, rec.Body.String())
+	require.Equal(t, []string{"backend/post1"}, rechecked)
+
+	tests := []struct {
+		name    string
+		postID  string
+		channel string
+		context map[string]string
+		want    int
+	}{
+		{
+			name:   "other date",
+			postID: "post1", channel: "ch1",
+			context: func() map[string]string {
+				c := signed(backend, "2023-09-01", expires)
+				c["date"] = "2023-09-02"
+				return c
+			}(),
+			want: http.StatusUnauthorized,
+		},
+		{
+			name:   "other channel signed",
+			postID: "post1", channel: "ch1",
+			context: signed(config.Team{Name: "backend", Channel: "ch2"}, "2023-09-01", expires),
+			want:    http.StatusUnauthorized,
+		},
+		{
+			name:   "expired",
+			postID: "post1", channel: "ch1",
+			context: signed(backend, "2023-09-01", strconv.FormatInt(time.Now().Add(-time.Hour).Unix(), 10)),
+			want:    http.StatusUnauthorized,
+		},
+		{
+			name:   "unknown team",
+			postID: "post1", channel: "ch1",
+			context: signed(config.Team{Name: "frontend", Channel: "ch1"}, "2023-09-01", expires),
+			want:    http.StatusNotFound,
+		},
+		{
+			name:   "no post",
+			postID: "", channel: "ch1",
+			context: signed(backend, "2023-09-01", expires),
+			want:    http.StatusBadRequest,
+		},
+		{
+			name:   "post of other channel",
+			postID: "post2", channel: "ch2",
+			context: signed(backend, "2023-09-01", expires),
+			want:    http.StatusUnauthorized,
+		},
+		{
+			name:   "team channel changed",
+			postID: "post2", channel: "ch2",
+			context: signed(config.Team{Name: "backend", Channel: "ch2"}, "2023-09-01", expires),
+			want:    http.StatusForbidden,
+		},
+		{
+			// team names are not revealed to unauthorized callers
+			name:   "unknown team unsigned",
+			postID: "post1", channel: "ch1",
+			context: map[string]string{"team": "frontend", "date": "2023-09-01", "expires": expires},
+			want:    http.StatusUnauthorized,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := recheck(tt.postID, tt.channel, tt.context)
+			require.Equal(t, tt.want, rec.Code)
+		})
+	}
+	require.Equal(t, []string{"backend/post1"}, rechecked)
+
+	// large bodies are rejected
+	req := httptest.NewRequest(http.MethodPost, "/actions/recheck",
+		strings.NewReader(`{"post_id": "`+strings.Repeat("a", maxBodySize)+`"}`))
+	rec = httptest.NewRecorder()
+	s.Handler().ServeHTTP(rec, req)
+	require.Equal(t, http.StatusRequestEntityTooLarge, rec.Code)
+}
+
+// failingTeams is a teams source failing to resolve some teams
+type failingTeams config.Teams
+
+func (t failingTeams) Teams(context.Context) (config.Teams, error) {
+	return config.Teams(t), errors.New("jira is down")
+}
+func TestServer_TeamsSourceError(t *testing.T) {
+	day := time.Date(2023, 9, 1, 0, 0, 0, 0, time.UTC)
+	s := newTestServer(t, day, model.WorkDay, nil)
+	s.teams = failingTeams{}
+
+	rec := serve(s, http.MethodGet, "/teams/backend/report", "Bearer "+token)
+	require.Equal(t, http.StatusBadGateway, rec.Code)
+	require.JSONEq(t, `{"error": "resolving teams: jira is down"}`, rec.Body.String())
+
+	// teams resolved are served while others fail
+	s.teams = failingTeams(testTeams)
+	rec = serve(s, http.MethodGet, "/teams/backend/report?date=2023-09-01", "Bearer "+token)
+	require.Equal(t, http.StatusOK, rec.Code)
+	rec = serve(s, http.MethodGet, "/members/acc1/spends?from=2023-09-01&to=2023-09-01", "Bearer "+token)
+	require.Equal(t, http.StatusOK, rec.Code)
+	rec = serve(s, http.MethodGet, "/teams/frontend/report", "Bearer "+token)
+	require.Equal(t, http.StatusBadGateway, rec.Code)
+}
@@ -0,0 +1,84 @@
+package httpapi
+
+import (
+	"github.com/duke0x/ts-notifier/config"
+	"github.com/duke0x/ts-notifier/tscalculator"
+)
+
+// member stores team member identifiers
+type member struct {
+	Name               string `json:"name"`
+	JiraAccID          string `json:"jira_account_id"`
+	MattermostUsername string `json:"mattermost_username"`
+}
+
+func newMember(m config.Member) member {
+	return member{
+		Name:               m.Name,
+		JiraAccID:          m.JiraAccID,
+		MattermostUsername: m.MattermostUsername,
+	}
+}
+
+// memberSpend stores member logged and remaining time for a day
+type memberSpend struct {
+	member
+	LoggedSeconds      int64 `json:"logged_seconds"`
+	RemainSpendSeconds int64 `json:"remain_spend_seconds"`
+}
+
+func newMemberSpend(mrs tscalculator.MemberRemainSpend) memberSpend {
+	return memberSpend{
+		member:             newMember(mrs.Member),
+		LoggedSeconds:      int64(mrs.LoggedSpend().Seconds()),
+		RemainSpendSeconds: int64(mrs.RemainSpend.Seconds()),
+	}
+}
+
+// teamReport is a 'GET /teams/{name}/report' response
+type teamReport struct {
+	Team               string        `json:"team"`
+	Date               string        `json:"date"`
+	WorkingDay         bool          `json:"working_day"`
+	RemainSpendSeconds int64         `json:"remain_spend_seconds"`
+	Members            []memberSpend `json:"members"`
+}
+
+// daySpend stores member logged and remaining time for a working day
+type daySpend struct {
+	Date               string `json:"date"`
+	LoggedSeconds      int64  `json:"logged_seconds"`
+	RemainSpendSeconds int64  `json:"remain_spend_seconds"`
+}
+
+// memberSpends is a 'GET /members/{id}/spends' response
+type memberSpends struct {
+	Member member     `json:"member"`
+	From   string     `json:"from"`
+	To     string     `json:"to"`
+	Days   []daySpend `json:"days"`
+}
+
+// notifyResult is a 'POST /teams/{name}/notify' response
+type notifyResult struct {
+	Team   string `json:"team"`
+	Date   string `json:"date"`
+	Status string `json:"status"`
+}
+
+// actionRequest is a Mattermost interactive message button request
+type actionRequest struct {
+	PostID    string            `json:"post_id"`
+	ChannelID string            `json:"channel_id"`
+	Context   map[string]string `json:"context"`
+}
+
+// actionResponse is an interactive message button response,
+// the ephemeral text is shown to the user who pressed the button
+type actionResponse struct {
+	EphemeralText string `json:"ephemeral_text,omitempty"`
+}
+
+type errorResponse struct {
+	Error string `json:"error"`
+}
@@ -0,0 +1,127 @@
+// Package identity finds missing Jira account IDs and Mattermost
+// usernames of the configured team members.
+package identity
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/duke0x/ts-notifier/client"
+	"github.com/duke0x/ts-notifier/config"
+	"github.com/duke0x/ts-notifier/model"
+)
+
+// Member fields which are filled
+const (
+	FieldJiraAccID          = "jira_account_id"
+	FieldMattermostUsername = "mattermost_username"
+	FieldEmail              = "email"
+)
+
+// JiraUsers searches Jira users
+type JiraUsers interface {
+	UserByEmail(ctx context.Context, email string) (model.Account, error)
+	UserByName(ctx context.Context, name string) (model.Account, error)
+}
+
+// ChatUsers searches chat users
+type ChatUsers interface {
+	UserByEmail(ctx context.Context, email string) (model.Account, error)
+}
+
+// Suggestion is a found value of the member field,
+// or the reason it is not found
+type Suggestion struct {
+	Team   int
+	Member int
+	// Name is a member name
+	Name  string
+	Field string
+	Value string
+	// Err is set if the value is not found
+	Err error
+}
+
+func (s Suggestion) String() string {
+	if s.Err != nil {
+		return fmt.Sprintf("! %s: %s", s.Field, s.Err)
+	}
+
+	return fmt.Sprintf("+ %s: %s", s.Field, s.Value)
+}
+
+type Resolver struct {
+	jira JiraUsers
+	chat ChatUsers
+}
+
+// New returns identity resolver, chat can be nil if Mattermost is not configured
+func New(jira JiraUsers, chat ChatUsers) *Resolver {
+	return &Resolver{jira: jira, chat: chat}
+}
+
+// Resolve returns suggestions for members missing Jira account ID
+// or Mattermost username. Members are searched by email, Jira accounts
+// also by name if email is not set. Not found users are reported
+// in suggestions, other errors abort resolving.
+func (r *Resolver) Resolve(ctx context.Context, teams config.Teams) ([]Suggestion, error) {
+	var ss []Suggestion
+	for ti, team := range teams {
+		for mi, m := range team.Members {
+			ms, err := r.resolveMember(ctx, m)
+			if err != nil {
+				return nil, fmt.Errorf("resolving team '%s' member '%s': %w", team.Name, m.Name, err)
+			}
+			for _, s := range ms {
+				s.Team, s.Member, s.Name = ti, mi, m.Name
+				ss = append(ss, s)
+			}
+		}
+	}
+
+	return ss, nil
+}
+
+func (r *Resolver) resolveMember(ctx context.Context, m config.Member) ([]Suggestion, error) {
+	var ss []Suggestion
+
+	email := m.Email
+	if m.JiraAccID == "" && (m.Email != "" || m.Name != "") {
+		var (
+			acc model.Account
+			err error
+		)
+		if m.Email != "" {
+			acc, err = r.jira.UserByEmail(ctx, m.Email)
+		} else {
+			acc, err = r.jira.UserByName(ctx, m.Name)
+		}
+		switch {
+		case errors.Is(err, client.ErrNotFound):
+			ss = append(ss, Suggestion{Field: FieldJiraAccID, Err: err})
+		case err != nil:
+			return nil, err
+		default:
+			ss = append(ss, Suggestion{Field: FieldJiraAccID, Value: acc.ID})
+			if email == "" && acc.Email != "" {
+				email = acc.Email
+				ss = append(ss, Suggestion{Field: FieldEmail, Value: email})
+			}
+		}
+	}
+
+	if m.MattermostUsername == "" && email != "" && r.chat != nil {
+		acc, err := r.chat.UserByEmail(ctx, email)
+		switch {
+		case errors.Is(err, client.ErrMattermostNotFound):
+			ss = append(ss, Suggestion{Field: FieldMattermostUsername, Err: err})
+		case err != nil:
+			return nil, err
+		default:
+			ss = append(ss, Suggestion{Field: FieldMattermostUsername, Value: acc.Username})
+		}
+	}
+
+	return ss, nil
+}
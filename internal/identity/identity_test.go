@@ -0,0 +1,85 @@
+package identity
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/duke0x/ts-notifier/client"
+	"github.com/duke0x/ts-notifier/config"
+	"github.com/duke0x/ts-notifier/model"
+)
+
+type fakeJira []model.Account
+
+func (f fakeJira) UserByEmail(_ context.Context, email string) (model.Account, error) {
+	for _, acc := range f {
+		if acc.Email == email {
+			return acc, nil
+		}
+	}
+	return model.Account{}, fmt.Errorf("%w: no user", client.ErrNotFound)
+}
+
+func (f fakeJira) UserByName(_ context.Context, name string) (model.Account, error) {
+	for _, acc := range f {
+		if acc.Name == name {
+			return acc, nil
+		}
+	}
+	return model.Account{}, fmt.Errorf("%w: no user", client.ErrNotFound)
+}
+
+type fakeChat map[string]string
+
+func (f fakeChat) UserByEmail(_ context.Context, email string) (model.Account, error) {
+	username, ok := f[email]
+	if !ok {
+		return model.Account{}, client.ErrMattermostNotFound
+	}
+	return model.Account{Username: username, Email: email}, nil
+}
+
+func TestResolver_Resolve(t *testing.T) {
+	jira := fakeJira{
+		{ID: "acc1", Name: "Ivan Ivanov", Email: "ivanov@myorg.com"},
+		{ID: "acc2", Name: "Petr Petrov", Email: "petrov@myorg.com"},
+	}
+	chat := fakeChat{"ivanov@myorg.com": "ivanov.i"}
+
+	got, err := New(jira, chat).Resolve(context.Background(), config.Teams{{
+		Name: "backend",
+		Members: []config.Member{
+			{Name: "Ivan", Email: "ivanov@myorg.com"},
+			{Name: "Petr Petrov"},
+			{Name: "Anna", JiraAccID: "acc3", MattermostUsername: "anna"},
+			{Name: "Unknown"},
+		},
+	}})
+	require.NoError(t, err)
+
+	// errors are compared by text
+	for i := range got {
+		if got[i].Err != nil {
+			require.True(t, errors.Is(got[i].Err, client.ErrNotFound) ||
+				errors.Is(got[i].Err, client.ErrMattermostNotFound))
+			got[i].Err = errors.New(got[i].Err.Error())
+		}
+	}
+	require.Equal(t, []Suggestion{
+		{Team: 0, Member: 0, Name: "Ivan", Field: FieldJiraAccID, Value: "acc1"},
+		{Team: 0, Member: 0, Name: "Ivan", Field: FieldMattermostUsername, Value: "ivanov.i"},
+		{Team: 0, Member: 1, Name: "Petr Petrov", Field: FieldJiraAccID, Value: "acc2"},
+		{Team: 0, Member: 1, Name: "Petr Petrov", Field: FieldEmail, Value: "petrov@myorg.com"},
+		{Team: 0, Member: 1, Name: "Petr Petrov", Field: FieldMattermostUsername,
+			Err: errors.New("mattermost resource not found")},
+		{Team: 0, Member: 3, Name: "Unknown", Field: FieldJiraAccID,
+			Err: errors.New("jira resource not found: no user")},
+	}, got)
+
+	require.Equal(t, "+ jira_account_id: acc1", got[0].String())
+	require.Equal(t, "! jira_account_id: jira resource not found: no user", got[5].String())
+}
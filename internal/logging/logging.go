@@ -0,0 +1,40 @@
+// Package logging creates structured application logger.
+package logging
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+
+	"github.com/duke0x/ts-notifier/config"
+)
+
+// Log output formats
+const (
+	FormatText = "text"
+	FormatJSON = "json"
+)
+
+var ErrUnknownFormat = errors.New("unknown log format")
+
+// New returns logger writing records to w with configured level and format.
+// Empty level and format are treated as 'info' and 'text'.
+func New(cfg config.Log, w io.Writer) (*slog.Logger, error) {
+	var level slog.Level
+	if cfg.Level != "" {
+		if err := level.UnmarshalText([]byte(cfg.Level)); err != nil {
+			return nil, fmt.Errorf("parsing log level: %w", err)
+		}
+	}
+
+	opts := &slog.HandlerOptions{Level: level}
+	switch cfg.Format {
+	case FormatText, "":
+		return slog.New(slog.NewTextHandler(w, opts)), nil
+	case FormatJSON:
+		return slog.New(slog.NewJSONHandler(w, opts)), nil
+	default:
+		return nil, fmt.Errorf("%w: '%s'", ErrUnknownFormat, cfg.Format)
+	}
+}
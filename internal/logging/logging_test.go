@@ -0,0 +1,46 @@
+package logging
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/duke0x/ts-notifier/config"
+)
+
+func TestNew(t *testing.T) {
+	t.Run("json debug", func(t *testing.T) {
+		var buf bytes.Buffer
+		l, err := New(config.Log{Level: "debug", Format: FormatJSON}, &buf)
+		require.NoError(t, err)
+
+		l.Debug("request sent", "status", 200)
+		var rec map[string]interface{}
+		require.NoError(t, json.Unmarshal(buf.Bytes(), &rec))
+		require.Equal(t, "DEBUG", rec["level"])
+		require.Equal(t, "request sent", rec["msg"])
+	})
+
+	t.Run("text info by default", func(t *testing.T) {
+		var buf bytes.Buffer
+		l, err := New(config.Log{}, &buf)
+		require.NoError(t, err)
+
+		l.Debug("hidden")
+		l.Info("shown")
+		require.NotContains(t, buf.String(), "hidden")
+		require.Contains(t, buf.String(), "level=INFO msg=shown")
+	})
+
+	t.Run("bad level", func(t *testing.T) {
+		_, err := New(config.Log{Level: "verbose"}, &bytes.Buffer{})
+		require.Error(t, err)
+	})
+
+	t.Run("bad format", func(t *testing.T) {
+		_, err := New(config.Log{Format: "xml"}, &bytes.Buffer{})
+		require.ErrorIs(t, err, ErrUnknownFormat)
+	})
+}
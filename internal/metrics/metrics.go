@@ -0,0 +1,202 @@
+// Package metrics collects the utility runtime metrics
+// and exposes them in Prometheus format.
+package metrics
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+
+	"github.com/duke0x/ts-notifier/client"
+	"github.com/duke0x/ts-notifier/model"
+	"github.com/duke0x/ts-notifier/tscalculator"
+)
+
+const namespace = "ts_notifier"
+
+// Services which requests latency is observed
+const (
+	ServiceJira       = "jira"
+	ServiceIsDayOff   = "isdayoff"
+	ServiceMattermost = "mattermost"
+)
+
+// errorLabels maps known errors to 'error' label values,
+// all other errors are counted as 'other'
+var errorLabels = []struct {
+	err   error
+	label string
+}{
+	{client.ErrServiceUnavailable, "service_unavailable"},
+	{client.ErrDataNotFound, "data_not_found"},
+	{client.ErrBadDayFormat, "bad_day_format"},
+	{client.ErrNonIntegerCode, "non_integer_code"},
+	{client.ErrUnknown, "unknown_code"},
+	{client.ErrUnauthorized, "unauthorized"},
+	{client.ErrForbidden, "forbidden"},
+	{client.ErrBadJQL, "bad_jql"},
+	{client.ErrNotFound, "not_found"},
+	{client.ErrRateLimited, "rate_limited"},
+	{client.ErrUnexpectedStatus, "unexpected_status"},
+	{tscalculator.ErrNonWorkingDay, "non_working_day"},
+	{context.DeadlineExceeded, "timeout"},
+}
+
+// Metrics stores the utility metrics collectors.
+// All methods of nil Metrics do nothing.
+type Metrics struct {
+	registry        *prometheus.Registry
+	remainSpend     *prometheus.GaugeVec
+	notifications   *prometheus.CounterVec
+	notifyFailures  *prometheus.CounterVec
+	requestDuration *prometheus.HistogramVec
+	errors          *prometheus.CounterVec
+}
+
+func New() *Metrics {
+	m := &Metrics{
+		registry: prometheus.NewRegistry(),
+		remainSpend: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Name:      "remain_spend_hours",
+			Help:      "Time remained to spend by team member on the last checked day.",
+		}, []string{"team", "jira_account_id"}),
+		notifications: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "notifications_sent_total",
+			Help:      "Number of notifications sent.",
+		}, []string{"notifier"}),
+		notifyFailures: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "notifications_failed_total",
+			Help:      "Number of notifications failed to send.",
+		}, []string{"notifier"}),
+		requestDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "http_request_duration_seconds",
+			Help:      "Latency of requests to external services.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"service", "code", "method"}),
+		errors: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "errors_total",
+			Help:      "Number of time spends check errors by kind.",
+		}, []string{"error"}),
+	}
+
+	m.registry.MustRegister(
+		m.remainSpend,
+		m.notifications,
+		m.notifyFailures,
+		m.requestDuration,
+		m.errors,
+	)
+
+	return m
+}
+
+// Handler returns metrics handler in Prometheus exposition format
+func (m *Metrics) Handler() http.Handler {
+	return promhttp.HandlerFor(m.registry, promhttp.HandlerOpts{})
+}
+
+// SetRemainSpend sets team member remained time to spend,
+// the member is labeled by Jira account identifier as names can be empty or repeated
+func (m *Metrics) SetRemainSpend(team, jiraAccID string, remain time.Duration) {
+	if m == nil {
+		return
+	}
+
+	m.remainSpend.WithLabelValues(team, jiraAccID).Set(remain.Hours())
+}
+
+// ObserveError counts error by its kind
+func (m *Metrics) ObserveError(err error) {
+	if m == nil || err == nil {
+		return
+	}
+
+	m.errors.WithLabelValues(errorLabel(err)).Inc()
+}
+
+func errorLabel(err error) string {
+	for _, el := range errorLabels {
+		if errors.Is(err, el.err) {
+			return el.label
+		}
+	}
+
+	return "other"
+}
+
+// Transport wraps next round tripper with the service requests latency observer
+func (m *Metrics) Transport(service string, next http.RoundTripper) http.RoundTripper {
+	if m == nil {
+		return next
+	}
+	if next == nil {
+		next = http.DefaultTransport
+	}
+
+	return promhttp.InstrumentRoundTripperDuration(
+		m.requestDuration.MustCurryWith(prometheus.Labels{"service": service}),
+		next,
+	)
+}
+
+// Notifier is a notifier which sent and failed notifications are counted
+type Notifier interface {
+	Notify(ctx context.Context, channel, message string) error
+	NotifyUser(ctx context.Context, username, message string) error
+	Post(ctx context.Context, channel, rootID string, msg model.Message) (string, error)
+	UpdatePost(ctx context.Context, postID string, msg model.Message) error
+}
+
+// countingNotifier counts notifications sent by the wrapped notifier
+type countingNotifier struct {
+	name    string
+	next    Notifier
+	metrics *Metrics
+}
+
+// Notifier wraps notifier with sent and failed notifications counters
+func (m *Metrics) Notifier(name string, next Notifier) Notifier {
+	if m == nil {
+		return next
+	}
+
+	return &countingNotifier{name: name, next: next, metrics: m}
+}
+
+func (n *countingNotifier) Notify(ctx context.Context, channel, message string) error {
+	return n.count(n.next.Notify(ctx, channel, message))
+}
+
+func (n *countingNotifier) NotifyUser(ctx context.Context, username, message string) error {
+	return n.count(n.next.NotifyUser(ctx, username, message))
+}
+
+func (n *countingNotifier) Post(ctx context.Context, channel, rootID string, msg model.Message) (string, error) {
+	id, err := n.next.Post(ctx, channel, rootID, msg)
+
+	return id, n.count(err)
+}
+
+func (n *countingNotifier) UpdatePost(ctx context.Context, postID string, msg model.Message) error {
+	return n.count(n.next.UpdatePost(ctx, postID, msg))
+}
+
+// count counts the notification by its sending error
+func (n *countingNotifier) count(err error) error {
+	if err != nil {
+		n.metrics.notifyFailures.WithLabelValues(n.name).Inc()
+		return err
+	}
+	n.metrics.notifications.WithLabelValues(n.name).Inc()
+
+	return nil
+}
@@ -0,0 +1,110 @@
+package metrics
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+
+	"github.com/duke0x/ts-notifier/client"
+	"github.com/duke0x/ts-notifier/model"
+)
+
+type notifierFunc func(channel, message string) error
+
+func (f notifierFunc) Notify(_ context.Context, channel, message string) error {
+	return f(channel, message)
+}
+
+func (f notifierFunc) NotifyUser(_ context.Context, username, message string) error {
+	return f("@"+username, message)
+}
+
+func (f notifierFunc) Post(_ context.Context, channel, _ string, msg model.Message) (string, error) {
+	return "", f(channel, msg.Text)
+}
+
+func (f notifierFunc) UpdatePost(_ context.Context, postID string, msg model.Message) error {
+	return f(postID, msg.Text)
+}
+
+func Test_errorLabel(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want string
+	}{
+		{"wrapped sentinel", fmt.Errorf("checking day: %w", client.ErrServiceUnavailable), "service_unavailable"},
+		{"not found", client.ErrDataNotFound, "data_not_found"},
+		{"unknown error", errors.New("boom"), "other"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.Equal(t, tt.want, errorLabel(tt.err))
+		})
+	}
+}
+
+func TestMetrics_Notifier(t *testing.T) {
+	m := New()
+	fail := false
+	n := m.Notifier("mattermost", notifierFunc(func(_, _ string) error {
+		if fail {
+			return errors.New("unavailable")
+		}
+		return nil
+	}))
+
+	require.NoError(t, n.Notify(context.Background(), "ch1", "report"))
+	require.NoError(t, n.Notify(context.Background(), "ch2", "report"))
+	fail = true
+	require.Error(t, n.Notify(context.Background(), "ch1", "report"))
+
+	require.Equal(t, 2.0, testutil.ToFloat64(m.notifications.WithLabelValues("mattermost")))
+	require.Equal(t, 1.0, testutil.ToFloat64(m.notifyFailures.WithLabelValues("mattermost")))
+}
+
+func TestMetrics_Handler(t *testing.T) {
+	m := New()
+	m.SetRemainSpend("backend", "acc1", 90*time.Minute)
+	m.ObserveError(fmt.Errorf("checking day: %w", client.ErrServiceUnavailable))
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer srv.Close()
+
+	c := &http.Client{Transport: m.Transport(ServiceJira, nil)}
+	resp, err := c.Get(srv.URL) //nolint:noctx
+	require.NoError(t, err)
+	_ = resp.Body.Close()
+
+	rec := httptest.NewRecorder()
+	m.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	body := rec.Body.String()
+
+	for _, want := range []string{
+		`ts_notifier_remain_spend_hours{jira_account_id="acc1",team="backend"} 1.5`,
+		`ts_notifier_errors_total{error="service_unavailable"} 1`,
+		`ts_notifier_http_request_duration_seconds_count{code="200",method="get",service="jira"} 1`,
+	} {
+		require.True(t, strings.Contains(body, want), "metric %q not found in:\n%s", want, body)
+	}
+}
+
+func TestMetrics_Nil(t *testing.T) {
+	var m *Metrics
+	n := notifierFunc(func(_, _ string) error { return nil })
+
+	m.SetRemainSpend("backend", "acc1", time.Hour)
+	m.ObserveError(errors.New("boom"))
+	require.Equal(t, http.DefaultTransport, m.Transport(ServiceJira, http.DefaultTransport))
+	require.NoError(t, m.Notifier("stdout", n).Notify(context.Background(), "ch1", "report"))
+}
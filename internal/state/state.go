@@ -0,0 +1,203 @@
+// Package state stores the tool state between runs in a JSON file.
+package state
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+const dayFormat = "2006-01-02"
+
+// missed stores member consecutive missed working days
+type missed struct {
+	// Days is a number of missed days in a row including LastDay
+	Days int `json:"days"`
+	// Before is a number of missed days in a row before LastDay,
+	// it makes repeated runs for the same day idempotent
+	Before int `json:"before"`
+	// LastDay is the last checked day
+	LastDay string `json:"last_day"`
+}
+
+// post stores the first report post of the day
+type post struct {
+	Day string `json:"day"`
+	ID  string `json:"id"`
+}
+
+type data struct {
+	// Missed are keyed by team and member
+	Missed map[string]missed `json:"missed"`
+	// Posts are keyed by team, only the last day post is kept
+	Posts map[string]post `json:"posts"`
+	// Notified are the last days member notifications were sent,
+	// they are keyed by team, member and notification kind
+	Notified map[string]string `json:"notified"`
+}
+
+// File is a state stored in the JSON file, every change is written at once
+type File struct {
+	path string
+	// readOnly state keeps changes in memory only
+	readOnly bool
+
+	mu   sync.Mutex
+	data data
+}
+
+// Open reads the state file, missing file is an empty state
+func Open(path string) (*File, error) {
+	f := &File{path: path, data: data{
+		Missed:   map[string]missed{},
+		Posts:    map[string]post{},
+		Notified: map[string]string{},
+	}}
+
+	raw, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return f, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("reading state file: %w", err)
+	}
+	if err := json.Unmarshal(raw, &f.data); err != nil {
+		return nil, fmt.Errorf("decoding state file: %w", err)
+	}
+	if f.data.Missed == nil {
+		f.data.Missed = map[string]missed{}
+	}
+	if f.data.Posts == nil {
+		f.data.Posts = map[string]post{}
+	}
+	if f.data.Notified == nil {
+		f.data.Notified = map[string]string{}
+	}
+
+	return f, nil
+}
+
+// OpenReadOnly reads the state file like Open, changes of the returned
+// state are kept in memory and never written, e.g. for dry runs
+func OpenReadOnly(path string) (*File, error) {
+	f, err := Open(path)
+	if err != nil {
+		return nil, err
+	}
+	f.readOnly = true
+
+	return f, nil
+}
+
+// MissedDays records whether the member missed the day and returns
+// the number of missed working days in a row ending with the day.
+// The row is continued only if the last checked day is the previous
+// working day prev. Days checked earlier than the last checked one
+// are not recorded.
+func (f *File) MissedDays(team, member string, day, prev time.Time, isMissed bool) (int, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	key := team + "/" + member
+	m := f.data.Missed[key]
+	ds := day.Format(dayFormat)
+	switch {
+	case ds < m.LastDay:
+		if isMissed {
+			return 1, nil
+		}
+		return 0, nil
+	case ds > m.LastDay:
+		m.Before = 0
+		if m.LastDay == prev.Format(dayFormat) {
+			m.Before = m.Days
+		}
+		m.LastDay = ds
+	}
+
+	m.Days = 0
+	if isMissed {
+		m.Days = m.Before + 1
+	}
+	f.data.Missed[key] = m
+
+	return m.Days, f.save()
+}
+
+// PostID returns identifier of the team report post of the day,
+// it is empty if nothing was posted for the day
+func (f *File) PostID(team string, day time.Time) string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	p := f.data.Posts[team]
+	if p.Day != day.Format(dayFormat) {
+		return ""
+	}
+
+	return p.ID
+}
+
+// SetPostID records identifier of the team report post of the day
+func (f *File) SetPostID(team string, day time.Time, id string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.data.Posts[team] = post{Day: day.Format(dayFormat), ID: id}
+
+	return f.save()
+}
+
+// Notified reports whether the member notification of the kind was sent for the day
+func (f *File) Notified(team, member, kind string, day time.Time) bool {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return f.data.Notified[team+"/"+member+"/"+kind] == day.Format(dayFormat)
+}
+
+// SetNotified records that the member notification of the kind was sent for the day
+func (f *File) SetNotified(team, member, kind string, day time.Time) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.data.Notified[team+"/"+member+"/"+kind] = day.Format(dayFormat)
+
+	return f.save()
+}
+
+// save writes the state to a temporary file and renames it,
+// so the state file is never partially written
+func (f *File) save() error {
+	if f.readOnly {
+		return nil
+	}
+
+	raw, err := json.MarshalIndent(f.data, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encoding state: %w", err)
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(f.path), filepath.Base(f.path)+".*")
+	if err != nil {
+		return fmt.Errorf("writing state file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(raw); err != nil {
+		_ = tmp.Close()
+		return fmt.Errorf("writing state file: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("writing state file: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), f.path); err != nil {
+		return fmt.Errorf("writing state file: %w", err)
+	}
+
+	return nil
+}
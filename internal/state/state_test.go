@@ -0,0 +1,136 @@
+package state
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestFile_MissedDays(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state.json")
+	// friday and the working days after the weekend
+	day := time.Date(2023, 9, 1, 0, 0, 0, 0, time.UTC)
+	monday, tuesday := day.AddDate(0, 0, 3), day.AddDate(0, 0, 4)
+
+	f, err := Open(path)
+	require.NoError(t, err)
+
+	days, err := f.MissedDays("backend", "acc1", day, day.AddDate(0, 0, -1), true)
+	require.NoError(t, err)
+	require.Equal(t, 1, days)
+
+	// repeated run for the same day is not counted twice
+	days, err = f.MissedDays("backend", "acc1", day, day.AddDate(0, 0, -1), true)
+	require.NoError(t, err)
+	require.Equal(t, 1, days)
+
+	// state survives reopening
+	f, err = Open(path)
+	require.NoError(t, err)
+	days, err = f.MissedDays("backend", "acc1", monday, day, true)
+	require.NoError(t, err)
+	require.Equal(t, 2, days)
+
+	// member logged time on rerun of the same day
+	days, err = f.MissedDays("backend", "acc1", monday, day, false)
+	require.NoError(t, err)
+	require.Equal(t, 0, days)
+
+	// earlier days are not recorded
+	days, err = f.MissedDays("backend", "acc1", day, day.AddDate(0, 0, -1), true)
+	require.NoError(t, err)
+	require.Equal(t, 1, days)
+
+	// members are counted separately
+	days, err = f.MissedDays("mobile", "acc1", tuesday, monday, true)
+	require.NoError(t, err)
+	require.Equal(t, 1, days)
+	days, err = f.MissedDays("backend", "acc1", tuesday, monday, true)
+	require.NoError(t, err)
+	require.Equal(t, 1, days)
+
+	// the row is broken by an unchecked working day
+	days, err = f.MissedDays("backend", "acc1", tuesday.AddDate(0, 0, 2), tuesday.AddDate(0, 0, 1), true)
+	require.NoError(t, err)
+	require.Equal(t, 1, days)
+}
+
+func TestFile_Notified(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state.json")
+	day := time.Date(2023, 9, 1, 0, 0, 0, 0, time.UTC)
+
+	f, err := Open(path)
+	require.NoError(t, err)
+	require.False(t, f.Notified("backend", "acc1", "reminder", day))
+
+	require.NoError(t, f.SetNotified("backend", "acc1", "reminder", day))
+	require.True(t, f.Notified("backend", "acc1", "reminder", day))
+	require.False(t, f.Notified("backend", "acc1", "manager", day))
+	require.False(t, f.Notified("backend", "acc2", "reminder", day))
+
+	// state survives reopening, only the last day is kept
+	f, err = Open(path)
+	require.NoError(t, err)
+	require.True(t, f.Notified("backend", "acc1", "reminder", day))
+	require.NoError(t, f.SetNotified("backend", "acc1", "reminder", day.AddDate(0, 0, 3)))
+	require.False(t, f.Notified("backend", "acc1", "reminder", day))
+}
+
+func TestFile_PostID(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state.json")
+	day := time.Date(2023, 9, 1, 0, 0, 0, 0, time.UTC)
+
+	f, err := Open(path)
+	require.NoError(t, err)
+	require.Empty(t, f.PostID("backend", day))
+
+	require.NoError(t, f.SetPostID("backend", day, "post1"))
+	require.Equal(t, "post1", f.PostID("backend", day))
+	require.Empty(t, f.PostID("mobile", day))
+
+	// state survives reopening, only the last day post is kept
+	f, err = Open(path)
+	require.NoError(t, err)
+	require.Equal(t, "post1", f.PostID("backend", day))
+	require.NoError(t, f.SetPostID("backend", day.AddDate(0, 0, 3), "post2"))
+	require.Empty(t, f.PostID("backend", day))
+	require.Equal(t, "post2", f.PostID("backend", day.AddDate(0, 0, 3)))
+}
+
+func TestOpenReadOnly(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state.json")
+	day := time.Date(2023, 9, 1, 0, 0, 0, 0, time.UTC)
+
+	f, err := Open(path)
+	require.NoError(t, err)
+	_, err = f.MissedDays("backend", "acc1", day, day.AddDate(0, 0, -1), true)
+	require.NoError(t, err)
+
+	stored, err := os.ReadFile(path)
+	require.NoError(t, err)
+
+	// the stored state is read, changes are seen in memory only
+	ro, err := OpenReadOnly(path)
+	require.NoError(t, err)
+	monday := day.AddDate(0, 0, 3)
+	days, err := ro.MissedDays("backend", "acc1", monday, day, true)
+	require.NoError(t, err)
+	require.Equal(t, 2, days)
+	require.NoError(t, ro.SetNotified("backend", "acc1", "reminder", monday))
+	require.True(t, ro.Notified("backend", "acc1", "reminder", monday))
+
+	got, err := os.ReadFile(path)
+	require.NoError(t, err)
+	require.Equal(t, string(stored), string(got))
+}
+
+func TestOpenBadFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state.json")
+	require.NoError(t, os.WriteFile(path, []byte("{"), 0o600))
+
+	_, err := Open(path)
+	require.Error(t, err)
+}
@@ -0,0 +1,101 @@
+package stdoutnotifier
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/duke0x/ts-notifier/model"
+)
+
+// DryRun prints messages with the notifier and channel they would be sent to
+// instead of sending them.
+type DryRun struct {
+	// Target is a name of the replaced notifier, e.g. 'mattermost'
+	Target string
+	// Attachments tells whether the replaced notifier sends message
+	// attachments, they are printed after the message text then
+	Attachments bool
+	// Out is a messages output, stdout by default
+	Out io.Writer
+}
+
+// statusNames are printed attachment statuses
+var statusNames = map[model.Status]string{
+	model.StatusOK:      "ok",
+	model.StatusWarning: "warning",
+	model.StatusAlert:   "alert",
+}
+
+// Notify prints the message preceded by its target notifier and channel.
+func (d *DryRun) Notify(_ context.Context, channel, message string) error {
+	return d.print("channel "+channel, message)
+}
+
+// NotifyUser prints the message preceded by its target notifier and user.
+func (d *DryRun) NotifyUser(ctx context.Context, username, message string) error {
+	return d.Notify(ctx, "@"+username, message)
+}
+
+// Post prints the message as the target notifier would send it preceded
+// by its target notifier and channel, there is no post identifier.
+func (d *DryRun) Post(_ context.Context, channel, _ string, msg model.Message) (string, error) {
+	return "", d.print("channel "+channel, d.render(msg))
+}
+
+// UpdatePost prints the message as the target notifier would send it
+// preceded by its target notifier and post.
+func (d *DryRun) UpdatePost(_ context.Context, postID string, msg model.Message) error {
+	return d.print("post "+postID, d.render(msg))
+}
+
+func (d *DryRun) print(to, message string) error {
+	out := d.Out
+	if out == nil {
+		out = os.Stdout
+	}
+	_, err := fmt.Fprintf(out, "--- %s, %s ---\n%s\n", d.Target, to, message)
+
+	return err
+}
+
+// render returns the message text followed by the attachments
+// with their fields and actions if the target sends attachments
+func (d *DryRun) render(msg model.Message) string {
+	text := msg.Content(d.Attachments)
+	if !d.Attachments || len(msg.Attachments) == 0 {
+		return text
+	}
+
+	var b strings.Builder
+	b.WriteString(text)
+	for _, a := range msg.Attachments {
+		fmt.Fprintf(&b, "\n[attachment %s]", statusNames[a.Status])
+		if a.Title != "" {
+			b.WriteString(" " + a.Title)
+		}
+		if a.Text != "" {
+			b.WriteString("\n" + a.Text)
+		}
+		for _, f := range a.Fields {
+			fmt.Fprintf(&b, "\n%s: %s", f.Title, f.Value)
+		}
+		for _, act := range a.Actions {
+			keys := make([]string, 0, len(act.Context))
+			for k := range act.Context {
+				keys = append(keys, k)
+			}
+			sort.Strings(keys)
+			params := make([]string, 0, len(keys))
+			for _, k := range keys {
+				params = append(params, k+"="+act.Context[k])
+			}
+			fmt.Fprintf(&b, "\n[button %s] %s %s", act.Name, act.URL, strings.Join(params, " "))
+		}
+	}
+
+	return b.String()
+}
@@ -0,0 +1,60 @@
+package stdoutnotifier
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/duke0x/ts-notifier/model"
+)
+
+func TestDryRun_Notify(t *testing.T) {
+	var out bytes.Buffer
+	n := &DryRun{Target: "mattermost", Out: &out}
+
+	require.NoError(t, n.Notify(context.Background(), "ch1", "report 1"))
+	require.NoError(t, n.Notify(context.Background(), "ch2", "report 2"))
+	require.Equal(t, "--- mattermost, channel ch1 ---\nreport 1\n"+
+		"--- mattermost, channel ch2 ---\nreport 2\n", out.String())
+}
+
+func TestDryRun_Post(t *testing.T) {
+	msg := model.Message{
+		Text:  "Не списано время:\n  1. @ivanov.i: 2h",
+		Title: "Не списано время: @ivanov.i",
+		Attachments: []model.Attachment{{
+			Status: model.StatusWarning,
+			Title:  "Ivan Ivanov",
+			Fields: []model.Field{{Title: "Осталось", Value: "2h", Short: true}},
+		}, {
+			Text: "Проверено в 18:00",
+			Actions: []model.Action{{
+				ID:      "recheck",
+				Name:    "Перепроверить",
+				URL:     "https://ts-notifier.myorg.com/actions/recheck",
+				Context: map[string]string{"team": "backend", "date": "2023-09-01"},
+			}},
+		}},
+	}
+
+	var out bytes.Buffer
+	n := &DryRun{Target: "mattermost", Out: &out}
+	_, err := n.Post(context.Background(), "ch1", "", msg)
+	require.NoError(t, err)
+	require.Equal(t, "--- mattermost, channel ch1 ---\nНе списано время:\n  1. @ivanov.i: 2h\n", out.String())
+
+	// attachments are printed as they would be sent
+	out.Reset()
+	n.Attachments = true
+	require.NoError(t, n.UpdatePost(context.Background(), "post1", msg))
+	require.Equal(t, "--- mattermost, post post1 ---\n"+
+		"Не списано время: @ivanov.i\n"+
+		"[attachment warning] Ivan Ivanov\n"+
+		"Осталось: 2h\n"+
+		"[attachment ok]\n"+
+		"Проверено в 18:00\n"+
+		"[button Перепроверить] https://ts-notifier.myorg.com/actions/recheck date=2023-09-01 team=backend\n",
+		out.String())
+}
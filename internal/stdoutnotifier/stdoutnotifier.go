@@ -2,13 +2,35 @@
 // standard output (stdout) printer.
 package stdoutnotifier
 
-import "fmt"
+import (
+	"context"
+	"fmt"
+
+	"github.com/duke0x/ts-notifier/model"
+)
 
 type StdOut struct{}
 
 // Notify prints message to stdout.
-func (t *StdOut) Notify(_, message string) error {
+func (t *StdOut) Notify(_ context.Context, _, message string) error {
 	_, err := fmt.Println(message)
 
 	return err
 }
+
+// NotifyUser prints message addressed to the user to stdout.
+func (t *StdOut) NotifyUser(_ context.Context, username, message string) error {
+	_, err := fmt.Printf("@%s: %s\n", username, message)
+
+	return err
+}
+
+// Post prints the plain text message to stdout, there is no post identifier.
+func (t *StdOut) Post(ctx context.Context, channel, _ string, msg model.Message) (string, error) {
+	return "", t.Notify(ctx, channel, msg.Text)
+}
+
+// UpdatePost prints the updated plain text message to stdout.
+func (t *StdOut) UpdatePost(ctx context.Context, _ string, msg model.Message) error {
+	return t.Notify(ctx, "", msg.Text)
+}
@@ -1,31 +1,91 @@
 package main
 
 import (
+	"context"
 	"errors"
 	"fmt"
+	"log/slog"
 	"net/http"
 	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
 
 	"github.com/duke0x/ts-notifier/client"
 	"github.com/duke0x/ts-notifier/config"
 	"github.com/duke0x/ts-notifier/internal/app"
+	"github.com/duke0x/ts-notifier/internal/discovery"
+	"github.com/duke0x/ts-notifier/internal/httpapi"
+	"github.com/duke0x/ts-notifier/internal/logging"
+	"github.com/duke0x/ts-notifier/internal/metrics"
+	"github.com/duke0x/ts-notifier/internal/state"
 	"github.com/duke0x/ts-notifier/internal/stdoutnotifier"
+	"github.com/duke0x/ts-notifier/tscalculator"
 )
 
+// discoveryInterval is a time the http service keeps discovered team members
+const discoveryInterval = 15 * time.Minute
+
 type errCode int
 
 const (
 	parseArgs  errCode = 1
 	readConfig errCode = 2
 	checkTS    errCode = 3
+	serveHTTP  errCode = 4
+	// partialFailure means some teams are processed and others failed
+	partialFailure errCode = 5
 )
 
+// exit logs the message with the default logger and exits with the code
 func exit(message string, code errCode) {
-	fmt.Println(message)
+	slog.Error(message)
 	os.Exit(int(code))
 }
 
+// teamsCode returns exit code of the teams processing error
+func teamsCode(err error) errCode {
+	if errors.Is(err, app.ErrPartialFailure) {
+		return partialFailure
+	}
+	// member selectors are checked against discovered members
+	if errors.Is(err, config.ErrUnknownMember) {
+		return parseArgs
+	}
+
+	return checkTS
+}
+
+// validate prints config problems to stdout and returns exit code
+func validate(path string, err error) errCode {
+	var ve *config.ValidationError
+	switch {
+	case err == nil:
+		fmt.Printf("%s: config is valid\n", path)
+		return 0
+	case errors.As(err, &ve):
+		// problems are printed in 'file:line: field: message' format
+		for _, p := range ve.Problems {
+			pos := path
+			if p.Line > 0 {
+				pos = fmt.Sprintf("%s:%d", path, p.Line)
+			}
+			p.Line = 0
+			fmt.Printf("%s: %s\n", pos, p)
+		}
+	default:
+		fmt.Printf("%s: %s\n", path, err.Error())
+	}
+
+	return readConfig
+}
+
 func main() {
+	// run is canceled on interrupt, so requests in flight are aborted
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
 	args, err := config.ProcessArgs(os.Args[1:])
 	if err != nil {
 		if errors.Is(err, config.ErrBadDayFormat) {
@@ -37,27 +97,162 @@ func main() {
 		exit("parsing config:"+err.Error(), parseArgs)
 	}
 
-	// read configuration from the file
-	cfg, err := config.ReadConfig(args.ConfigPath)
+	if args.Timeout > 0 && args.Command != config.CmdHTTP {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, args.Timeout)
+		defer cancel()
+	}
+
+	// read configuration from the file, secrets can be kept in Vault
+	vault := vaultProvider()
+	if args.Command == config.CmdResolveMembers {
+		if err := resolveMembers(ctx, args, vault); err != nil {
+			exit(fmt.Sprintf("resolving members: %s", err.Error()), readConfig)
+		}
+		return
+	}
+	cfg, err := config.ReadConfig(ctx, args.ConfigPath, vault)
+	if args.Command == config.CmdValidate {
+		os.Exit(int(validate(args.ConfigPath, err)))
+	}
 	if err != nil {
 		exit(fmt.Sprintf("reading config file: %s", err.Error()), readConfig)
 	}
 
+	// logs go to stderr to keep stdout for reports
+	logger, err := logging.New(cfg.Log, os.Stderr)
+	if err != nil {
+		exit(fmt.Sprintf("configuring logger: %s", err.Error()), readConfig)
+	}
+	slog.SetDefault(logger)
+
 	// initialize dependencies
-	do := client.NewIsDayOff(&http.Client{}, client.IsDayOffURL)
-	jira := client.NewJiraCli(&http.Client{}, cfg.Jira)
+	m := metrics.New()
+	do := client.NewIsDayOff(
+		&http.Client{Transport: m.Transport(metrics.ServiceIsDayOff, nil)},
+		cfg.IsDayOff,
+		logger,
+	)
+	mm := client.NewNotifier(
+		&http.Client{Transport: m.Transport(metrics.ServiceMattermost, nil)},
+		cfg.Mattermost,
+		logger,
+	)
 	var n app.Notifier
-	n = client.NewNotifier(&http.Client{}, cfg.Mattermost)
-	if cfg.Mattermost.URL == "" {
-		n = &stdoutnotifier.StdOut{}
+	target := metrics.ServiceMattermost
+	n = m.Notifier(target, mm)
+	reportOnly := args.Command == config.CmdStats || args.Command == config.CmdLate
+	// members check themselves without notifying the team
+	if cfg.Mattermost.URL == "" || (reportOnly && !args.Notify) || len(args.Members) > 0 {
+		target = "stdout"
+		n = m.Notifier(target, &stdoutnotifier.StdOut{})
+	}
+	if args.DryRun {
+		n = &stdoutnotifier.DryRun{
+			Target:      target,
+			Attachments: target == metrics.ServiceMattermost && cfg.Mattermost.Attachments,
+		}
+	}
+
+	// fail alerts admins about the failure before exit
+	fail := func(title string, err error, code errCode) {
+		if aerr := app.Alert(ctx, n, cfg.Admin, title, err); aerr != nil {
+			slog.Error("notify admins about failure", "error", aerr)
+		}
+		exit(fmt.Sprintf("%s: %s", title, err.Error()), code)
+	}
+
+	jira, err := client.NewJiraCli(
+		&http.Client{Transport: m.Transport(metrics.ServiceJira, nil)},
+		cfg.Jira,
+		logger,
+	)
+	if err != nil {
+		fail("configuring jira client", err, readConfig)
+	}
+
+	// only selected teams are processed, members discovered in Jira
+	// and Mattermost are added to every team when it is processed,
+	// so a team failed to resolve does not stop others
+	if cfg.Teams, err = cfg.Teams.Select(args.Teams, nil); err != nil {
+		exit(fmt.Sprintf("selecting teams: %s", err.Error()), parseArgs)
+	}
+	var chat discovery.ChatDirectory
+	if cfg.Mattermost.URL != "" {
+		chat = mm
+	}
+	resolver := discovery.New(jira, chat, logger)
+
+	if args.Command == config.CmdMe {
+		teams, err := resolver.ResolveTeams(ctx, cfg.Teams)
+		if err != nil {
+			logger.Warn("searching the user in resolved teams only", "error", err)
+		}
+		if err := me(ctx, args, teams, jira, tscalculator.New(do, jira, logger)); err != nil {
+			exit(fmt.Sprintf("checking user time spends: %s", err.Error()), checkTS)
+		}
+		return
+	}
+
+	// dry run and members checks do not change the state,
+	// dry run reads it to print escalations that would be sent
+	var store app.StateStore
+	if cfg.State.Path != "" && len(args.Members) == 0 {
+		open := state.Open
+		if args.DryRun {
+			open = state.OpenReadOnly
+		}
+		st, err := open(cfg.State.Path)
+		if err != nil {
+			fail("opening state", err, readConfig)
+		}
+		store = st
+	}
+
+	a := app.NewCliApp(args, cfg, do, jira, n, store, resolver, m, logger)
+
+	switch args.Command {
+	case config.CmdStats:
+		if err := a.RunStats(ctx); err != nil {
+			exit(
+				fmt.Sprintf("calculate time spends stats: %s", err.Error()),
+				teamsCode(err),
+			)
+		}
+	case config.CmdHTTP:
+		// members are discovered again periodically to follow membership changes,
+		// members selection is not allowed for the command
+		teams := discovery.NewCache(resolver, cfg.Teams, discoveryInterval)
+		srv := httpapi.New(cfg.Server, teams, tscalculator.New(do, jira, logger), a, m, m.Handler())
+		logger.Info("serving http api", "listen", cfg.Server.Listen)
+		if err := srv.ListenAndServe(ctx); err != nil {
+			fail("serving http api", err, serveHTTP)
+		}
+	case config.CmdLate:
+		if err := a.RunLate(ctx); err != nil {
+			exit(
+				fmt.Sprintf("search late work logs: %s", err.Error()),
+				teamsCode(err),
+			)
+		}
+	default:
+		if err := a.Run(ctx); err != nil {
+			exit(
+				fmt.Sprintf("check remaining time spends & notify: %s", err.Error()),
+				teamsCode(err),
+			)
+		}
 	}
+}
 
-	a := app.NewCliApp(args, cfg, do, jira, n)
-	// a := app.NewCliApp(args, cfg, do, jira, tn)
-	if err := a.Run(); err != nil {
-		exit(
-			fmt.Sprintf("check remaining time spends & notify: %s", err.Error()),
-			checkTS,
-		)
+// vaultProvider returns Vault secrets provider configured by VAULT_ADDR
+// and VAULT_TOKEN environment variables. 'file://<path>' address
+// reads secrets from the local file instead of Vault server.
+func vaultProvider() config.SecretsProvider {
+	addr := os.Getenv("VAULT_ADDR")
+	if path, ok := strings.CutPrefix(addr, client.LocalVaultAddrScheme); ok {
+		return client.NewLocalVault(path)
 	}
+
+	return client.NewVault(&http.Client{}, addr, os.Getenv("VAULT_TOKEN"), nil)
 }
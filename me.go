@@ -0,0 +1,49 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/duke0x/ts-notifier/client"
+	"github.com/duke0x/ts-notifier/config"
+	"github.com/duke0x/ts-notifier/tscalculator"
+)
+
+// me prints the user logged time and remained time spends of the period.
+// The user is searched in teams first to apply the team settings,
+// unknown user email is searched in Jira.
+func me(ctx context.Context, args config.Args, teams config.Teams, jira *client.Jira, tsc *tscalculator.TSCalc) error {
+	team, member, ok := findMember(teams, args.User)
+	if !ok {
+		member = config.Member{Name: args.User, JiraAccID: args.User}
+		if strings.Contains(args.User, "@") {
+			acc, err := jira.UserByEmail(ctx, args.User)
+			if err != nil {
+				return err
+			}
+			member = config.Member{Name: acc.Name, JiraAccID: acc.ID, Email: args.User}
+		}
+	}
+
+	spends, err := tsc.CalcMemberSpends(ctx, args.From, args.To, team, member)
+	if err != nil {
+		return err
+	}
+	fmt.Print(spends.Report(args.From, args.To))
+
+	return nil
+}
+
+// findMember returns the first team member with the Jira account ID or email
+func findMember(teams config.Teams, user string) (config.Team, config.Member, bool) {
+	for _, team := range teams {
+		for _, member := range team.Members {
+			if strings.EqualFold(member.JiraAccID, user) || strings.EqualFold(member.Email, user) {
+				return team, member, true
+			}
+		}
+	}
+
+	return config.Team{}, config.Member{}, false
+}
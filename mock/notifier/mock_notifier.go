@@ -5,8 +5,10 @@
 package mock_notifier
 
 import (
+	context "context"
 	reflect "reflect"
 
+	model "github.com/duke0x/ts-notifier/model"
 	gomock "github.com/golang/mock/gomock"
 )
 
@@ -34,15 +36,58 @@ func (m *MockNotifier) EXPECT() *MockNotifierMockRecorder {
 }
 
 // Notify mocks base method.
-func (m *MockNotifier) Notify(arg0, arg1 string) error {
+func (m *MockNotifier) Notify(arg0 context.Context, arg1, arg2 string) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Notify", arg0, arg1)
+	ret := m.ctrl.Call(m, "Notify", arg0, arg1, arg2)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // Notify indicates an expected call of Notify.
-func (mr *MockNotifierMockRecorder) Notify(arg0, arg1 interface{}) *gomock.Call {
+func (mr *MockNotifierMockRecorder) Notify(arg0, arg1, arg2 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Notify", reflect.TypeOf((*MockNotifier)(nil).Notify), arg0, arg1)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Notify", reflect.TypeOf((*MockNotifier)(nil).Notify), arg0, arg1, arg2)
+}
+
+// NotifyUser mocks base method.
+func (m *MockNotifier) NotifyUser(arg0 context.Context, arg1, arg2 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "NotifyUser", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// NotifyUser indicates an expected call of NotifyUser.
+func (mr *MockNotifierMockRecorder) NotifyUser(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "NotifyUser", reflect.TypeOf((*MockNotifier)(nil).NotifyUser), arg0, arg1, arg2)
+}
+
+// Post mocks base method.
+func (m *MockNotifier) Post(arg0 context.Context, arg1, arg2 string, arg3 model.Message) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Post", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Post indicates an expected call of Post.
+func (mr *MockNotifierMockRecorder) Post(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Post", reflect.TypeOf((*MockNotifier)(nil).Post), arg0, arg1, arg2, arg3)
+}
+
+// UpdatePost mocks base method.
+func (m *MockNotifier) UpdatePost(arg0 context.Context, arg1 string, arg2 model.Message) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdatePost", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdatePost indicates an expected call of UpdatePost.
+func (mr *MockNotifierMockRecorder) UpdatePost(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdatePost", reflect.TypeOf((*MockNotifier)(nil).UpdatePost), arg0, arg1, arg2)
 }
@@ -37,18 +37,18 @@ func (m *MockWorkLogFetcher) EXPECT() *MockWorkLogFetcherMockRecorder {
 }
 
 // UserWorkedIssuesByDate mocks base method.
-func (m *MockWorkLogFetcher) UserWorkedIssuesByDate(arg0 context.Context, arg1 model.User, arg2 time.Time) ([]model.Issue, error) {
+func (m *MockWorkLogFetcher) UserWorkedIssuesByDate(arg0 context.Context, arg1 model.User, arg2 time.Time, arg3 string) ([]model.Issue, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "UserWorkedIssuesByDate", arg0, arg1, arg2)
+	ret := m.ctrl.Call(m, "UserWorkedIssuesByDate", arg0, arg1, arg2, arg3)
 	ret0, _ := ret[0].([]model.Issue)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // UserWorkedIssuesByDate indicates an expected call of UserWorkedIssuesByDate.
-func (mr *MockWorkLogFetcherMockRecorder) UserWorkedIssuesByDate(arg0, arg1, arg2 interface{}) *gomock.Call {
+func (mr *MockWorkLogFetcherMockRecorder) UserWorkedIssuesByDate(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UserWorkedIssuesByDate", reflect.TypeOf((*MockWorkLogFetcher)(nil).UserWorkedIssuesByDate), arg0, arg1, arg2)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UserWorkedIssuesByDate", reflect.TypeOf((*MockWorkLogFetcher)(nil).UserWorkedIssuesByDate), arg0, arg1, arg2, arg3)
 }
 
 // WorkLogsPerIssues mocks base method.
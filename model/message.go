@@ -0,0 +1,62 @@
+package model
+
+// Status is a state of a message part, notifiers render it in their own way,
+// e.g. Mattermost colors attachments by it.
+type Status int
+
+const (
+	// StatusOK means nothing needs attention.
+	StatusOK Status = iota
+	// StatusWarning means something needs attention.
+	StatusWarning
+	// StatusAlert means something is wrong.
+	StatusAlert
+)
+
+// Message is a notification message with optional rich content.
+type Message struct {
+	// Text is a plain text message, it is sent by notifiers without rich messages.
+	Text string
+	// Title is a message text sent along with the attachments instead of Text,
+	// Text is sent with them if Title is empty.
+	Title string
+	// Attachments are rich message parts.
+	Attachments []Attachment
+}
+
+// Content returns the message text sent by the notifier, rich tells
+// whether the notifier sends attachments.
+func (m Message) Content(rich bool) string {
+	if !rich || len(m.Attachments) == 0 || m.Title == "" {
+		return m.Text
+	}
+
+	return m.Title
+}
+
+// Attachment is a rich message part.
+type Attachment struct {
+	Status Status
+	Title  string
+	Text   string
+	Fields []Field
+	// Actions are buttons calling back the tool.
+	Actions []Action
+}
+
+// Field is a titled attachment value.
+type Field struct {
+	Title string
+	Value string
+	// Short fields are shown side by side.
+	Short bool
+}
+
+// Action is a message button, pressing it calls the URL back with the context.
+type Action struct {
+	// ID identifies the action in the message, it must be alphanumeric.
+	ID      string
+	Name    string
+	URL     string
+	Context map[string]string
+}
@@ -1,3 +1,15 @@
 package model
 
 type User string
+
+// Account stores user account data of an external service.
+type Account struct {
+	// ID is an account identifier, e.g. Jira account ID.
+	ID string `json:"id"`
+	// Name is a user display name.
+	Name string `json:"name"`
+	// Username is a user login name, e.g. Mattermost username.
+	Username string `json:"username"`
+	// Email is a user email, it can be hidden by the service.
+	Email string `json:"email"`
+}
@@ -9,6 +9,12 @@ type Issue struct {
 	// Key is a Jira issue identifier, written in user-friendly format.
 	// Format: <project>-<task number>. Example: PRJ-1.
 	Key string `json:"key"`
+	// Summary is an issue title.
+	Summary string `json:"summary,omitempty"`
+	// Project is an issue project key. Example: PRJ.
+	Project string `json:"project,omitempty"`
+	// Epic is an issue epic key, empty if the issue is out of epics.
+	Epic string `json:"epic,omitempty"`
 }
 
 // WorkLog stores work log specific data.
@@ -23,6 +29,10 @@ type WorkLog struct {
 	Started time.Time `json:"started"`
 	// Comment is a short message 'what was done'.
 	Comment string `json:"comment"`
+	// Created is a time when the record was written.
+	Created time.Time `json:"created"`
+	// Updated is a time when the record was changed last time.
+	Updated time.Time `json:"updated"`
 }
 
 // Date returns work log date only
@@ -31,3 +41,13 @@ func (wl WorkLog) Date() time.Time {
 
 	return wl.Started.Truncate(time.Hour * hoursPerDay)
 }
+
+// Lateness returns how long after the work start the record was written.
+// Records written before the start or without creation time have zero lateness.
+func (wl WorkLog) Lateness() time.Duration {
+	if wl.Created.IsZero() || wl.Created.Before(wl.Started) {
+		return 0
+	}
+
+	return wl.Created.Sub(wl.Started)
+}
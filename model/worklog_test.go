@@ -47,3 +47,27 @@ func TestWorkLog_Date(t *testing.T) {
 		})
 	}
 }
+
+func TestWorkLog_Lateness(t *testing.T) {
+	started := time.Date(2023, 9, 1, 10, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name    string
+		created time.Time
+		want    time.Duration
+	}{
+		{"no created time", time.Time{}, 0},
+		{"written before work start", started.Add(-time.Hour), 0},
+		{"written at work start", started, 0},
+		{"written at work end", started.Add(2 * time.Hour), 2 * time.Hour},
+		{"written 3 days later", started.Add(72 * time.Hour), 72 * time.Hour},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			wl := WorkLog{TimeSpentSeconds: 7200, Started: started, Created: tt.created}
+			if got := wl.Lateness(); got != tt.want {
+				t.Errorf("Lateness() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,83 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"os"
+
+	"github.com/duke0x/ts-notifier/client"
+	"github.com/duke0x/ts-notifier/config"
+	"github.com/duke0x/ts-notifier/internal/identity"
+	"github.com/duke0x/ts-notifier/internal/logging"
+)
+
+// resolveMembers finds missing member identifiers and prints them
+// as suggestions or writes them to the config file
+func resolveMembers(ctx context.Context, args config.Args, providers ...config.SecretsProvider) error {
+	f, err := config.ReadFile(ctx, args.ConfigPath, providers...)
+	if err != nil {
+		return fmt.Errorf("reading config file: %w", err)
+	}
+	cfg := f.Params
+
+	logger, err := logging.New(cfg.Log, os.Stderr)
+	if err != nil {
+		return fmt.Errorf("configuring logger: %w", err)
+	}
+	slog.SetDefault(logger)
+
+	jira, err := client.NewJiraCli(&http.Client{}, cfg.Jira, logger)
+	if err != nil {
+		return fmt.Errorf("configuring jira client: %w", err)
+	}
+	var chat identity.ChatUsers
+	if cfg.Mattermost.URL != "" {
+		chat = client.NewNotifier(&http.Client{}, cfg.Mattermost, logger)
+	}
+
+	suggestions, err := identity.New(jira, chat).Resolve(ctx, cfg.Teams)
+	if err != nil {
+		return err
+	}
+	if len(suggestions) == 0 {
+		fmt.Println("all team members identifiers are set")
+		return nil
+	}
+
+	// suggestions are grouped by member
+	var found int
+	for i, s := range suggestions {
+		if i == 0 || s.Team != suggestions[i-1].Team || s.Member != suggestions[i-1].Member {
+			fmt.Printf("teams[%d].members[%d] %s:\n", s.Team, s.Member, s.Name)
+		}
+		fmt.Printf("  %s\n", s)
+		if s.Err != nil {
+			continue
+		}
+		found++
+		if err := f.SetMemberField(s.Team, s.Member, s.Field, s.Value); err != nil {
+			return err
+		}
+	}
+
+	if !args.Write || found == 0 {
+		return nil
+	}
+
+	data, err := f.Bytes()
+	if err != nil {
+		return err
+	}
+	fi, err := os.Stat(args.ConfigPath)
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(args.ConfigPath, data, fi.Mode().Perm()); err != nil {
+		return fmt.Errorf("writing config file: %w", err)
+	}
+	fmt.Printf("%d values written to %s\n", found, args.ConfigPath)
+
+	return nil
+}
@@ -0,0 +1,143 @@
+package tscalculator
+
+import (
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/duke0x/ts-notifier/model"
+)
+
+// noEpic is a breakdown group of issues out of epics
+const noEpic = "без эпика"
+
+// IssueSpend stores time logged to the issue
+type IssueSpend struct {
+	Issue model.Issue
+	Spent time.Duration
+}
+
+// EpicSpend stores time logged to the epic issues
+type EpicSpend struct {
+	// Epic is an epic key, empty for issues out of epics
+	Epic   string
+	Spent  time.Duration
+	Issues []IssueSpend
+}
+
+// ProjectSpend stores time logged to the project issues grouped by epics
+type ProjectSpend struct {
+	Project string
+	Spent   time.Duration
+	Epics   []EpicSpend
+}
+
+// Breakdown groups member work logs by project, epic and issue.
+// Groups are sorted by keys, issues out of epics go last.
+func (mrs MemberRemainSpend) Breakdown() []ProjectSpend {
+	issues := make(map[string]model.Issue, len(mrs.Issues))
+	for _, issue := range mrs.Issues {
+		issues[issue.Key] = issue
+	}
+
+	spent := make(map[string]time.Duration)
+	for _, wl := range mrs.WorkLogs {
+		if _, ok := issues[wl.Key]; !ok {
+			issues[wl.Key] = model.Issue{Key: wl.Key}
+		}
+		spent[wl.Key] += time.Duration(wl.TimeSpentSeconds) * time.Second
+	}
+
+	keys := make([]string, 0, len(spent))
+	for key := range spent {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		a, b := issues[keys[i]], issues[keys[j]]
+		if pa, pb := issueProject(a), issueProject(b); pa != pb {
+			return pa < pb
+		}
+		if a.Epic != b.Epic {
+			return b.Epic == "" || (a.Epic != "" && a.Epic < b.Epic)
+		}
+		return a.Key < b.Key
+	})
+
+	var bd []ProjectSpend
+	for _, key := range keys {
+		issue, is := issues[key], IssueSpend{Issue: issues[key], Spent: spent[key]}
+
+		if len(bd) == 0 || bd[len(bd)-1].Project != issueProject(issue) {
+			bd = append(bd, ProjectSpend{Project: issueProject(issue)})
+		}
+		ps := &bd[len(bd)-1]
+		ps.Spent += is.Spent
+
+		if len(ps.Epics) == 0 || ps.Epics[len(ps.Epics)-1].Epic != issue.Epic {
+			ps.Epics = append(ps.Epics, EpicSpend{Epic: issue.Epic})
+		}
+		es := &ps.Epics[len(ps.Epics)-1]
+		es.Spent += is.Spent
+		es.Issues = append(es.Issues, is)
+	}
+
+	return bd
+}
+
+// issueProject returns issue project key, it is taken
+// from the issue key if Jira has not returned the project
+func issueProject(issue model.Issue) string {
+	if issue.Project != "" {
+		return issue.Project
+	}
+	project, _, _ := strings.Cut(issue.Key, "-")
+
+	return project
+}
+
+// DetailedReport returns the day report followed by logged time
+// of every member grouped by project, epic and issue
+func (trs TeamRemainSpends) DetailedReport(day time.Time, reminded Reminded) string {
+	var report strings.Builder
+	report.WriteString(trs.Report(day, reminded))
+	if !strings.HasSuffix(report.String(), "\n") {
+		report.WriteString("\n")
+	}
+
+	header := true
+	for _, mrs := range trs {
+		bd := mrs.Breakdown()
+		if len(bd) == 0 {
+			continue
+		}
+		if header {
+			header = false
+			report.WriteString("\nСписано по задачам:\n")
+		}
+
+		var total time.Duration
+		for _, ps := range bd {
+			total += ps.Spent
+		}
+		report.WriteString(reminded.mention(mrs.Member) + " — " + total.String() + "\n")
+		for _, ps := range bd {
+			report.WriteString("  " + ps.Project + " — " + ps.Spent.String() + "\n")
+			for _, es := range ps.Epics {
+				epic := es.Epic
+				if epic == "" {
+					epic = noEpic
+				}
+				report.WriteString("    " + epic + " — " + es.Spent.String() + "\n")
+				for _, is := range es.Issues {
+					report.WriteString("      " + is.Issue.Key)
+					if is.Issue.Summary != "" {
+						report.WriteString(" " + is.Issue.Summary)
+					}
+					report.WriteString(" — " + is.Spent.String() + "\n")
+				}
+			}
+		}
+	}
+
+	return report.String()
+}
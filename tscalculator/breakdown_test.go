@@ -0,0 +1,87 @@
+package tscalculator
+
+import (
+	"testing"
+	"time"
+
+	"github.com/duke0x/ts-notifier/config"
+	"github.com/duke0x/ts-notifier/model"
+	"github.com/stretchr/testify/require"
+)
+
+func testBreakdownSpend() MemberRemainSpend {
+	return MemberRemainSpend{
+		Member:      config.Member{MattermostUsername: "ivanov.i"},
+		RemainSpend: 1 * time.Hour,
+		Issues: []model.Issue{
+			{Key: "PRJ-1", Summary: "Login page", Project: "PRJ", Epic: "PRJ-10"},
+			{Key: "PRJ-2", Summary: "Review", Project: "PRJ"},
+			{Key: "OPS-1", Summary: "Deploy", Project: "OPS", Epic: "OPS-5"},
+		},
+		WorkLogs: []model.WorkLog{
+			{Key: "PRJ-2", TimeSpentSeconds: 1800},
+			{Key: "PRJ-1", TimeSpentSeconds: 3 * 3600},
+			{Key: "OPS-1", TimeSpentSeconds: 3600},
+			{Key: "PRJ-1", TimeSpentSeconds: 3600},
+			{Key: "DOC-1", TimeSpentSeconds: 1800},
+		},
+	}
+}
+
+func TestMemberRemainSpend_Breakdown(t *testing.T) {
+	require.Equal(t, []ProjectSpend{{
+		Project: "DOC",
+		Spent:   30 * time.Minute,
+		Epics: []EpicSpend{{
+			Spent:  30 * time.Minute,
+			Issues: []IssueSpend{{Issue: model.Issue{Key: "DOC-1"}, Spent: 30 * time.Minute}},
+		}},
+	}, {
+		Project: "OPS",
+		Spent:   time.Hour,
+		Epics: []EpicSpend{{
+			Epic:  "OPS-5",
+			Spent: time.Hour,
+			Issues: []IssueSpend{{
+				Issue: model.Issue{Key: "OPS-1", Summary: "Deploy", Project: "OPS", Epic: "OPS-5"},
+				Spent: time.Hour,
+			}},
+		}},
+	}, {
+		Project: "PRJ",
+		Spent:   4*time.Hour + 30*time.Minute,
+		Epics: []EpicSpend{{
+			Epic:  "PRJ-10",
+			Spent: 4 * time.Hour,
+			Issues: []IssueSpend{{
+				Issue: model.Issue{Key: "PRJ-1", Summary: "Login page", Project: "PRJ", Epic: "PRJ-10"},
+				Spent: 4 * time.Hour,
+			}},
+		}, {
+			Spent: 30 * time.Minute,
+			Issues: []IssueSpend{{
+				Issue: model.Issue{Key: "PRJ-2", Summary: "Review", Project: "PRJ"},
+				Spent: 30 * time.Minute,
+			}},
+		}},
+	}}, testBreakdownSpend().Breakdown())
+
+	require.Empty(t, MemberRemainSpend{}.Breakdown())
+}
+
+func TestTeamRemainSpends_DetailedReport(t *testing.T) {
+	day := time.Date(2023, 9, 1, 0, 0, 0, 0, time.UTC)
+	mrs := testBreakdownSpend()
+	mrs.WorkLogs = mrs.WorkLogs[:2]
+
+	require.Equal(t, "Отчет по списанию времени за 2023.09.01:\n"+
+		"  - @ivanov.i нужно списать еще 1h0m0s.\n"+
+		"\nСписано по задачам:\n"+
+		"@ivanov.i — 3h30m0s\n"+
+		"  PRJ — 3h30m0s\n"+
+		"    PRJ-10 — 3h0m0s\n"+
+		"      PRJ-1 Login page — 3h0m0s\n"+
+		"    без эпика — 30m0s\n"+
+		"      PRJ-2 Review — 30m0s\n",
+		TeamRemainSpends{mrs}.DetailedReport(day, nil))
+}
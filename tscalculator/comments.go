@@ -0,0 +1,86 @@
+package tscalculator
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/duke0x/ts-notifier/config"
+	"github.com/duke0x/ts-notifier/model"
+)
+
+// CommentViolation is a work log breaking the comment rules
+type CommentViolation struct {
+	WorkLog model.WorkLog
+	// Problems describe broken rules
+	Problems []string
+}
+
+func (cv CommentViolation) String() string {
+	spent := time.Duration(cv.WorkLog.TimeSpentSeconds) * time.Second
+
+	return cv.WorkLog.Key + " (" + spent.String() + "): " + strings.Join(cv.Problems, ", ")
+}
+
+// commentChecker checks work log comments with the compiled rules
+type commentChecker struct {
+	rules   config.CommentRules
+	pattern *regexp.Regexp
+}
+
+func newCommentChecker(rules config.CommentRules) (commentChecker, error) {
+	cc := commentChecker{rules: rules}
+	if rules.Pattern != "" {
+		p, err := regexp.Compile(rules.Pattern)
+		if err != nil {
+			return commentChecker{}, err
+		}
+		cc.pattern = p
+	}
+
+	return cc, nil
+}
+
+// check returns violations of the work logs, nil if rules are not set
+func (cc commentChecker) check(wls []model.WorkLog) []CommentViolation {
+	if !cc.rules.Enabled() {
+		return nil
+	}
+
+	var cvs []CommentViolation
+	for _, wl := range wls {
+		if problems := cc.problems(wl.Comment); len(problems) > 0 {
+			cvs = append(cvs, CommentViolation{WorkLog: wl, Problems: problems})
+		}
+	}
+
+	return cvs
+}
+
+func (cc commentChecker) problems(comment string) []string {
+	comment = strings.TrimSpace(comment)
+	if comment == "" {
+		if cc.rules.Required || cc.rules.MinLength > 0 || cc.pattern != nil {
+			return []string{"пустой комментарий"}
+		}
+		return nil
+	}
+
+	var problems []string
+	if utf8.RuneCountInString(comment) < cc.rules.MinLength {
+		problems = append(problems, "комментарий короче "+strconv.Itoa(cc.rules.MinLength)+" символов")
+	}
+	for _, f := range cc.rules.Forbidden {
+		if strings.EqualFold(comment, strings.TrimSpace(f)) {
+			problems = append(problems, "недопустимый комментарий '"+comment+"'")
+			break
+		}
+	}
+	if cc.pattern != nil && !cc.pattern.MatchString(comment) {
+		problems = append(problems, "комментарий не соответствует шаблону '"+cc.rules.Pattern+"'")
+	}
+
+	return problems
+}
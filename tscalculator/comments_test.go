@@ -0,0 +1,72 @@
+package tscalculator
+
+import (
+	"testing"
+	"time"
+
+	"github.com/duke0x/ts-notifier/config"
+	"github.com/duke0x/ts-notifier/model"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_commentChecker_problems(t *testing.T) {
+	tests := []struct {
+		name    string
+		rules   config.CommentRules
+		comment string
+		want    []string
+	}{
+		{"no rules", config.CommentRules{}, "", nil},
+		{"required", config.CommentRules{Required: true}, "  ", []string{"пустой комментарий"}},
+		{"not required empty", config.CommentRules{Forbidden: []string{"work"}}, "", nil},
+		{"short", config.CommentRules{MinLength: 10}, "фикс бага", []string{"комментарий короче 10 символов"}},
+		{"long enough", config.CommentRules{MinLength: 9}, "фикс бага", nil},
+		{"forbidden", config.CommentRules{Forbidden: []string{"work"}}, " Work ", []string{"недопустимый комментарий 'Work'"}},
+		{
+			name:    "pattern mismatch",
+			rules:   config.CommentRules{Pattern: `[A-Z]+-\d+`},
+			comment: "review",
+			want:    []string{`комментарий не соответствует шаблону '[A-Z]+-\d+'`},
+		},
+		{"pattern match", config.CommentRules{Pattern: `[A-Z]+-\d+`}, "review PRJ-12", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cc, err := newCommentChecker(tt.rules)
+			require.NoError(t, err)
+			require.Equal(t, tt.want, cc.problems(tt.comment))
+		})
+	}
+}
+
+func Test_commentChecker_check(t *testing.T) {
+	_, err := newCommentChecker(config.CommentRules{Pattern: "[A-Z"})
+	require.Error(t, err)
+
+	cc, err := newCommentChecker(config.CommentRules{Required: true})
+	require.NoError(t, err)
+
+	wls := []model.WorkLog{
+		{Key: "PRJ-1", TimeSpentSeconds: 3600, Comment: "code review"},
+		{Key: "PRJ-2", TimeSpentSeconds: 1800},
+	}
+	cvs := cc.check(wls)
+	require.Equal(t, []CommentViolation{{WorkLog: wls[1], Problems: []string{"пустой комментарий"}}}, cvs)
+	require.Equal(t, "PRJ-2 (30m0s): пустой комментарий", cvs[0].String())
+}
+
+func TestTeamRemainSpends_ReportCommentViolations(t *testing.T) {
+	day := time.Date(2023, 9, 1, 0, 0, 0, 0, time.UTC)
+	trs := TeamRemainSpends{{
+		Member: config.Member{MattermostUsername: "ivanov.i"},
+		CommentViolations: []CommentViolation{{
+			WorkLog:  model.WorkLog{Key: "PRJ-1", TimeSpentSeconds: 3600, Comment: "work"},
+			Problems: []string{"недопустимый комментарий 'work'"},
+		}},
+	}}
+
+	require.Equal(t, "Отчет по списанию времени за 2023.09.01:\n"+
+		"Все молодцы, все списания произведены! :)\n"+
+		"Замечания к комментариям списаний:\n"+
+		"  - @ivanov.i: PRJ-1 (1h0m0s): недопустимый комментарий 'work'.\n", trs.Report(day, nil))
+}
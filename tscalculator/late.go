@@ -0,0 +1,90 @@
+package tscalculator
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/duke0x/ts-notifier/config"
+	"github.com/duke0x/ts-notifier/model"
+)
+
+// MemberLateLogs stores team member work logs written retroactively
+type MemberLateLogs struct {
+	Member   config.Member
+	WorkLogs []model.WorkLog
+}
+
+// TeamLateLogs stores all team members work logs written retroactively
+type TeamLateLogs struct {
+	From    time.Time
+	To      time.Time
+	Members []MemberLateLogs
+	// Threshold is a lateness after which work log is treated as late
+	Threshold time.Duration
+}
+
+// Report returns the list of late work logs per member
+func (tll TeamLateLogs) Report() string {
+	var report strings.Builder
+	report.WriteString("Списания, внесенные с опозданием более " +
+		tll.Threshold.String() + ", за " + tll.From.Format("2006.01.02") +
+		" - " + tll.To.Format("2006.01.02") + ":\n")
+
+	emptyReport := true
+	for _, mll := range tll.Members {
+		for _, wl := range mll.WorkLogs {
+			emptyReport = false
+			report.WriteString("  - " + mll.Member.Mention() + " " +
+				wl.Key + " за " + wl.Started.Format("2006.01.02") + ": " +
+				(time.Duration(wl.TimeSpentSeconds) * time.Second).String() +
+				", внесено " + wl.Created.Format("2006.01.02") +
+				" (опоздание " + strconv.Itoa(int(wl.Lateness()/(time.Hour*hoursPerDay))) +
+				" дн.).\n")
+		}
+	}
+
+	if emptyReport {
+		report.WriteString("Все списания внесены вовремя! :)")
+	}
+
+	return report.String()
+}
+
+// CalcLateWorkLogs returns team members work logs for the period
+// from the first to the last day inclusive, which were written
+// later than threshold after the work start.
+func (tsc TSCalc) CalcLateWorkLogs(
+	ctx context.Context,
+	from time.Time,
+	to time.Time,
+	team config.Team,
+	threshold time.Duration,
+) (TeamLateLogs, error) {
+	tll := TeamLateLogs{
+		From:      from,
+		To:        to,
+		Members:   make([]MemberLateLogs, len(team.Members)),
+		Threshold: threshold,
+	}
+	for i, member := range team.Members {
+		tll.Members[i].Member = member
+	}
+
+	err := tsc.forEachWorkingDay(ctx, from, to, team, func(_ time.Time, trs TeamRemainSpends) {
+		for i, mrs := range trs {
+			for _, wl := range mrs.WorkLogs {
+				if wl.Lateness() > threshold {
+					tll.Members[i].WorkLogs = append(tll.Members[i].WorkLogs, wl)
+				}
+			}
+		}
+	})
+	if err != nil {
+		return TeamLateLogs{}, fmt.Errorf("searching late work logs: %w", err)
+	}
+
+	return tll, nil
+}
@@ -0,0 +1,103 @@
+package tscalculator
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/duke0x/ts-notifier/config"
+	mock_day_type_fetcher "github.com/duke0x/ts-notifier/mock/day_type_fetcher"
+	mock_worklog_fetcher "github.com/duke0x/ts-notifier/mock/work_log_fetcher"
+	"github.com/duke0x/ts-notifier/model"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTSCalc_CalcLateWorkLogs(t *testing.T) {
+	ctx := context.Background()
+
+	ctrl := gomock.NewController(t)
+	dc := mock_day_type_fetcher.NewMockDayTypeFetcher(ctrl)
+	wlf := mock_worklog_fetcher.NewMockWorkLogFetcher(ctrl)
+
+	member := config.Member{Name: "user1", JiraAccID: "user1_Jira_ID"}
+	team := config.Team{Name: "team1", Members: []config.Member{member}}
+	user := model.User(member.JiraAccID)
+	day := time.Date(2023, 9, 1, 0, 0, 0, 0, time.UTC)
+
+	inTime := model.WorkLog{
+		Key:              "PRJ-1",
+		User:             user,
+		TimeSpentSeconds: 3600,
+		Started:          day.Add(10 * time.Hour),
+		Created:          day.Add(12 * time.Hour),
+	}
+	late := model.WorkLog{
+		Key:              "PRJ-2",
+		User:             user,
+		TimeSpentSeconds: 3600,
+		Started:          day.Add(12 * time.Hour),
+		Created:          day.Add(4 * 24 * time.Hour),
+	}
+	issues := []model.Issue{{ID: "1", Key: "PRJ-1"}, {ID: "2", Key: "PRJ-2"}}
+
+	dc.EXPECT().FetchDayType(ctx, day).Return(model.WorkDay, nil)
+	wlf.EXPECT().UserWorkedIssuesByDate(ctx, user, day, "").Return(issues, nil)
+	wlf.EXPECT().WorkLogsPerIssues(
+		ctx, user, day, day.Add(time.Hour*23+time.Minute*59+time.Second*59), issues,
+	).Return([]model.WorkLog{inTime, late}, nil)
+
+	got, err := New(dc, wlf, nil).CalcLateWorkLogs(ctx, day, day, team, 24*time.Hour)
+	require.NoError(t, err)
+	require.Equal(t, TeamLateLogs{
+		From:      day,
+		To:        day,
+		Members:   []MemberLateLogs{{Member: member, WorkLogs: []model.WorkLog{late}}},
+		Threshold: 24 * time.Hour,
+	}, got)
+}
+
+func TestTeamLateLogs_Report(t *testing.T) {
+	day := time.Date(2023, 9, 1, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name string
+		tll  TeamLateLogs
+		want string
+	}{
+		{
+			name: "no late work logs",
+			tll: TeamLateLogs{
+				From:      day,
+				To:        day,
+				Members:   []MemberLateLogs{{Member: config.Member{MattermostUsername: "ivanov.i"}}},
+				Threshold: 24 * time.Hour,
+			},
+			want: "Списания, внесенные с опозданием более 24h0m0s, за 2023.09.01 - 2023.09.01:\n" +
+				"Все списания внесены вовремя! :)",
+		},
+		{
+			name: "1 late work log",
+			tll: TeamLateLogs{
+				From: day,
+				To:   day,
+				Members: []MemberLateLogs{{
+					Member: config.Member{MattermostUsername: "ivanov.i"},
+					WorkLogs: []model.WorkLog{{
+						Key:              "PRJ-1",
+						TimeSpentSeconds: 3600,
+						Started:          day.Add(10 * time.Hour),
+						Created:          day.Add(3*24*time.Hour + 11*time.Hour),
+					}},
+				}},
+				Threshold: 24 * time.Hour,
+			},
+			want: "Списания, внесенные с опозданием более 24h0m0s, за 2023.09.01 - 2023.09.01:\n" +
+				"  - @ivanov.i PRJ-1 за 2023.09.01: 1h0m0s, внесено 2023.09.04 (опоздание 3 дн.).\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.Equal(t, tt.want, tt.tll.Report())
+		})
+	}
+}
@@ -0,0 +1,75 @@
+package tscalculator
+
+import (
+	"strings"
+	"time"
+
+	"github.com/duke0x/ts-notifier/model"
+)
+
+// Status returns the member state by the remaining time: OK if the whole
+// day is logged, warning if at most a half of the day remains, alert otherwise
+func (mrs MemberRemainSpend) Status() model.Status {
+	switch {
+	case mrs.RemainSpend == 0:
+		return model.StatusOK
+	case mrs.RemainSpend <= hoursPerWorkingDay*time.Hour/2:
+		return model.StatusWarning
+	default:
+		return model.StatusAlert
+	}
+}
+
+// Message returns the daily report with an attachment per member,
+// members with remaining time are mentioned in the title
+// unless they were reminded directly
+func (trs TeamRemainSpends) Message(day time.Time, reminded Reminded) model.Message {
+	title := "Отчет по списанию времени за " + day.Format("2006.01.02")
+	var mentions []string
+	for _, mrs := range trs {
+		if mrs.RemainSpend > 0 {
+			mentions = append(mentions, reminded.mention(mrs.Member))
+		}
+	}
+	if len(mentions) > 0 {
+		title += ", нужно списать: " + strings.Join(mentions, ", ")
+	}
+
+	msg := model.Message{
+		Text:        trs.Report(day, reminded),
+		Title:       title,
+		Attachments: make([]model.Attachment, 0, len(trs)),
+	}
+	for _, mrs := range trs {
+		att := model.Attachment{
+			Status: mrs.Status(),
+			Title:  mrs.Member.Name,
+			Fields: []model.Field{
+				{Title: "Списано", Value: mrs.LoggedSpend().String(), Short: true},
+				{Title: "Осталось", Value: mrs.RemainSpend.String(), Short: true},
+			},
+		}
+		if reminded[mrs.Member.JiraAccID] && mrs.RemainSpend > 0 {
+			att.Text = "Напоминание отправлено лично"
+		}
+		if len(mrs.OutOfScope) > 0 {
+			att.Fields = append(att.Fields, model.Field{
+				Title: "Вне задач команды",
+				Value: mrs.OutOfScopeSpend().String() + " (" + issueKeys(mrs.OutOfScope) + ")",
+			})
+		}
+		if len(mrs.CommentViolations) > 0 {
+			violations := make([]string, 0, len(mrs.CommentViolations))
+			for _, cv := range mrs.CommentViolations {
+				violations = append(violations, cv.String())
+			}
+			att.Fields = append(att.Fields, model.Field{
+				Title: "Замечания к комментариям",
+				Value: strings.Join(violations, "\n"),
+			})
+		}
+		msg.Attachments = append(msg.Attachments, att)
+	}
+
+	return msg
+}
@@ -0,0 +1,67 @@
+package tscalculator
+
+import (
+	"testing"
+	"time"
+
+	"github.com/duke0x/ts-notifier/config"
+	"github.com/duke0x/ts-notifier/model"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTeamRemainSpends_Message(t *testing.T) {
+	day := time.Date(2023, 8, 31, 0, 0, 0, 0, time.UTC)
+	logged := func(key string, hours int) model.WorkLog {
+		return model.WorkLog{Key: key, User: "acc", TimeSpentSeconds: hours * 3600, Started: day}
+	}
+	trs := TeamRemainSpends{
+		{
+			Member:   config.Member{Name: "Ivan Ivanov", MattermostUsername: "ivanov.i"},
+			WorkLogs: []model.WorkLog{logged("PRJ-1", 8)},
+		},
+		{
+			Member:            config.Member{Name: "Petr Petrov", MattermostUsername: "petrov.p"},
+			RemainSpend:       3 * time.Hour,
+			WorkLogs:          []model.WorkLog{logged("PRJ-2", 5)},
+			OutOfScope:        []model.WorkLog{logged("OPS-1", 1)},
+			CommentViolations: []CommentViolation{{WorkLog: logged("PRJ-2", 5), Problems: []string{"empty comment"}}},
+		},
+		{
+			Member:      config.Member{Name: "Sidor Sidorov", MattermostUsername: "sidorov.s"},
+			RemainSpend: 8 * time.Hour,
+		},
+	}
+
+	require.Equal(t, model.Message{
+		Text:  trs.Report(day, nil),
+		Title: "Отчет по списанию времени за 2023.08.31, нужно списать: @petrov.p, @sidorov.s",
+		Attachments: []model.Attachment{
+			{
+				Status: model.StatusOK,
+				Title:  "Ivan Ivanov",
+				Fields: []model.Field{
+					{Title: "Списано", Value: "8h0m0s", Short: true},
+					{Title: "Осталось", Value: "0s", Short: true},
+				},
+			},
+			{
+				Status: model.StatusWarning,
+				Title:  "Petr Petrov",
+				Fields: []model.Field{
+					{Title: "Списано", Value: "5h0m0s", Short: true},
+					{Title: "Осталось", Value: "3h0m0s", Short: true},
+					{Title: "Вне задач команды", Value: "1h0m0s (OPS-1)"},
+					{Title: "Замечания к комментариям", Value: "PRJ-2 (5h0m0s): empty comment"},
+				},
+			},
+			{
+				Status: model.StatusAlert,
+				Title:  "Sidor Sidorov",
+				Fields: []model.Field{
+					{Title: "Списано", Value: "0s", Short: true},
+					{Title: "Осталось", Value: "8h0m0s", Short: true},
+				},
+			},
+		},
+	}, trs.Message(day, nil))
+}
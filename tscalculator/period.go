@@ -0,0 +1,125 @@
+package tscalculator
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/duke0x/ts-notifier/config"
+)
+
+// DayRemainSpend stores member remain time spend for a working day
+type DayRemainSpend struct {
+	Day time.Time
+	MemberRemainSpend
+}
+
+// MemberSpends stores member remain time spends of the period working days
+type MemberSpends []DayRemainSpend
+
+// RemainSpend returns time remained to spend in the period
+func (ms MemberSpends) RemainSpend() time.Duration {
+	var total time.Duration
+	for _, drs := range ms {
+		total += drs.RemainSpend
+	}
+
+	return total
+}
+
+// Report returns member logged time per issue and remained time
+// for every working day of the period
+func (ms MemberSpends) Report(from, to time.Time) string {
+	var report strings.Builder
+	report.WriteString("Ваши списания за " + from.Format("2006.01.02"))
+	if !to.Equal(from) {
+		report.WriteString(" - " + to.Format("2006.01.02"))
+	}
+	report.WriteString(":\n")
+
+	if len(ms) == 0 {
+		report.WriteString("Рабочих дней нет.\n")
+		return report.String()
+	}
+
+	for _, drs := range ms {
+		var logged time.Duration
+		for _, wl := range drs.WorkLogs {
+			logged += time.Duration(wl.TimeSpentSeconds) * time.Second
+		}
+		report.WriteString(drs.Day.Format("2006.01.02") + ": списано " + logged.String())
+		if drs.RemainSpend > 0 {
+			report.WriteString(", нужно списать еще " + drs.RemainSpend.String())
+		}
+		report.WriteString("\n")
+
+		for _, ps := range drs.Breakdown() {
+			for _, es := range ps.Epics {
+				for _, is := range es.Issues {
+					report.WriteString("  - " + is.Issue.Key)
+					if is.Issue.Summary != "" {
+						report.WriteString(" " + is.Issue.Summary)
+					}
+					report.WriteString(": " + is.Spent.String() + "\n")
+				}
+			}
+		}
+	}
+
+	if remain := ms.RemainSpend(); remain > 0 {
+		report.WriteString("Всего нужно списать еще " + remain.String() + ".\n")
+	} else {
+		report.WriteString("Все списано! :)\n")
+	}
+
+	return report.String()
+}
+
+// CalcMemberSpends returns member remain time spends for every working day
+// of the period from the first to the last day inclusive.
+// Work logs are checked with the team settings, e.g. its scope.
+func (tsc TSCalc) CalcMemberSpends(
+	ctx context.Context,
+	from time.Time,
+	to time.Time,
+	team config.Team,
+	member config.Member,
+) (MemberSpends, error) {
+	team.Members = []config.Member{member}
+
+	var spends MemberSpends
+	err := tsc.forEachWorkingDay(ctx, from, to, team, func(day time.Time, trs TeamRemainSpends) {
+		spends = append(spends, DayRemainSpend{Day: day, MemberRemainSpend: trs[0]})
+	})
+	if err != nil {
+		return nil, fmt.Errorf("calculating member spends: %w", err)
+	}
+
+	return spends, nil
+}
+
+// forEachWorkingDay calculates team remain spends for every working day
+// of the period from the first to the last day inclusive and passes them to fn.
+func (tsc TSCalc) forEachWorkingDay(
+	ctx context.Context,
+	from time.Time,
+	to time.Time,
+	team config.Team,
+	fn func(day time.Time, trs TeamRemainSpends),
+) error {
+	for day := from; !day.After(to); day = day.AddDate(0, 0, 1) {
+		trs, err := tsc.CalcDailyTimeSpends(ctx, day, team)
+		if errors.Is(err, ErrNonWorkingDay) {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+
+		fn(day, trs)
+	}
+
+	return nil
+}
@@ -0,0 +1,84 @@
+package tscalculator
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/duke0x/ts-notifier/config"
+	mock_day_type_fetcher "github.com/duke0x/ts-notifier/mock/day_type_fetcher"
+	mock_worklog_fetcher "github.com/duke0x/ts-notifier/mock/work_log_fetcher"
+	"github.com/duke0x/ts-notifier/model"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTSCalc_CalcMemberSpends(t *testing.T) {
+	ctx := context.Background()
+
+	ctrl := gomock.NewController(t)
+	dc := mock_day_type_fetcher.NewMockDayTypeFetcher(ctrl)
+	wlf := mock_worklog_fetcher.NewMockWorkLogFetcher(ctrl)
+
+	from := time.Date(2023, 9, 1, 0, 0, 0, 0, time.UTC)
+	to := from.AddDate(0, 0, 1)
+	member := config.Member{Name: "Ivan Ivanov", JiraAccID: "acc1"}
+	team := config.Team{
+		Name:    "backend",
+		Members: []config.Member{{Name: "Petr Petrov", JiraAccID: "acc2"}, member},
+		Scope:   config.Scope{Projects: []string{"PRJ"}},
+	}
+
+	dc.EXPECT().FetchDayType(ctx, from).Return(model.WorkDay, nil)
+	dc.EXPECT().FetchDayType(ctx, to).Return(model.NoWorkDay, nil)
+
+	issues := []model.Issue{{ID: "1", Key: "PRJ-1"}}
+	wls := []model.WorkLog{{Key: "PRJ-1", User: "acc1", TimeSpentSeconds: 5 * 3600, Started: from}}
+	wlf.EXPECT().UserWorkedIssuesByDate(ctx, model.User("acc1"), from, `project in ("PRJ")`).Return(issues, nil)
+	wlf.EXPECT().UserWorkedIssuesByDate(ctx, model.User("acc1"), from, "").Return(issues, nil)
+	wlf.EXPECT().WorkLogsPerIssues(ctx, model.User("acc1"), from, from.Add(24*time.Hour-time.Second), issues).
+		Return(wls, nil)
+
+	got, err := New(dc, wlf, nil).CalcMemberSpends(ctx, from, to, team, member)
+	require.NoError(t, err)
+	require.Equal(t, MemberSpends{{
+		Day: from,
+		MemberRemainSpend: MemberRemainSpend{
+			Member:      member,
+			RemainSpend: 3 * time.Hour,
+			WorkLogs:    wls,
+			Issues:      issues,
+		},
+	}}, got)
+}
+
+func TestMemberSpends_Report(t *testing.T) {
+	day := time.Date(2023, 9, 1, 0, 0, 0, 0, time.UTC)
+	ms := MemberSpends{{
+		Day: day,
+		MemberRemainSpend: MemberRemainSpend{
+			RemainSpend: 2 * time.Hour,
+			Issues:      []model.Issue{{Key: "PRJ-1", Summary: "Login page"}},
+			WorkLogs: []model.WorkLog{
+				{Key: "PRJ-1", TimeSpentSeconds: 4 * 3600},
+				{Key: "PRJ-2", TimeSpentSeconds: 2 * 3600},
+			},
+		},
+	}, {
+		Day: day.AddDate(0, 0, 3),
+		MemberRemainSpend: MemberRemainSpend{
+			WorkLogs: []model.WorkLog{{Key: "PRJ-2", TimeSpentSeconds: 8 * 3600}},
+		},
+	}}
+
+	require.Equal(t, "Ваши списания за 2023.09.01 - 2023.09.04:\n"+
+		"2023.09.01: списано 6h0m0s, нужно списать еще 2h0m0s\n"+
+		"  - PRJ-1 Login page: 4h0m0s\n"+
+		"  - PRJ-2: 2h0m0s\n"+
+		"2023.09.04: списано 8h0m0s\n"+
+		"  - PRJ-2: 8h0m0s\n"+
+		"Всего нужно списать еще 2h0m0s.\n", ms.Report(day, day.AddDate(0, 0, 3)))
+
+	require.Equal(t, "Ваши списания за 2023.09.02:\nРабочих дней нет.\n",
+		MemberSpends{}.Report(day.AddDate(0, 0, 1), day.AddDate(0, 0, 1)))
+}
@@ -0,0 +1,162 @@
+package tscalculator
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/duke0x/ts-notifier/config"
+)
+
+// MemberStats stores team member time spends compliance for a period
+type MemberStats struct {
+	Member config.Member
+	// WorkingDays is a number of working days in the period
+	WorkingDays int
+	// CompliantDays is a number of working days with all time spends written
+	CompliantDays int
+	// MissingSpend is a total time remained to spend in the period
+	MissingSpend time.Duration
+	// LongestStreak is the longest run of compliant working days in a row
+	LongestStreak int
+	// CurrentStreak is a run of compliant working days which ends
+	// on the last working day of the period
+	CurrentStreak int
+	// WorkLogs is a number of work logs written in the period
+	WorkLogs int
+	// TotalLateness is a sum of all work logs lateness
+	TotalLateness time.Duration
+	// OutOfScope is a total time logged to issues out of the team scope
+	OutOfScope time.Duration
+}
+
+// AvgLateness returns an average time between work start and its logging
+func (ms MemberStats) AvgLateness() time.Duration {
+	if ms.WorkLogs == 0 {
+		return 0
+	}
+
+	return ms.TotalLateness / time.Duration(ms.WorkLogs)
+}
+
+// ComplianceRate returns a share of compliant working days, from 0 to 1.
+// A period without working days is fully compliant.
+func (ms MemberStats) ComplianceRate() float64 {
+	if ms.WorkingDays == 0 {
+		return 1
+	}
+
+	return float64(ms.CompliantDays) / float64(ms.WorkingDays)
+}
+
+// addDay accounts one working day remain spend in member stats
+func (ms *MemberStats) addDay(mrs MemberRemainSpend) {
+	ms.WorkingDays++
+	ms.MissingSpend += mrs.RemainSpend
+	ms.OutOfScope += mrs.OutOfScopeSpend()
+
+	for _, wl := range mrs.WorkLogs {
+		ms.WorkLogs++
+		ms.TotalLateness += wl.Lateness()
+	}
+
+	if mrs.RemainSpend > 0 {
+		ms.CurrentStreak = 0
+		return
+	}
+
+	ms.CompliantDays++
+	ms.CurrentStreak++
+	if ms.CurrentStreak > ms.LongestStreak {
+		ms.LongestStreak = ms.CurrentStreak
+	}
+}
+
+// TeamStats stores all team members compliance for a period
+type TeamStats struct {
+	From    time.Time
+	To      time.Time
+	Members []MemberStats
+}
+
+// Leaderboard returns members ordered from the most compliant one.
+// Members with equal compliance rate are ordered by missing spend.
+func (ts TeamStats) Leaderboard() []MemberStats {
+	lb := make([]MemberStats, len(ts.Members))
+	copy(lb, ts.Members)
+
+	sort.SliceStable(lb, func(i, j int) bool {
+		ri, rj := lb[i].ComplianceRate(), lb[j].ComplianceRate()
+		if ri != rj {
+			return ri > rj
+		}
+
+		return lb[i].MissingSpend < lb[j].MissingSpend
+	})
+
+	return lb
+}
+
+// Report returns team compliance summary with members leaderboard
+func (ts TeamStats) Report() string {
+	const percents = 100
+
+	var report strings.Builder
+	report.WriteString("Статистика списания времени за " +
+		ts.From.Format("2006.01.02") + " - " + ts.To.Format("2006.01.02") + ":\n")
+
+	for i, ms := range ts.Leaderboard() {
+		report.WriteString("  " + strconv.Itoa(i+1) + ". " + ms.Member.Mention() +
+			": списано в срок " + strconv.Itoa(ms.CompliantDays) + " из " +
+			strconv.Itoa(ms.WorkingDays) + " дн. (" +
+			strconv.Itoa(int(math.Round(ms.ComplianceRate()*percents))) + "%)" +
+			", не списано " + ms.MissingSpend.String() +
+			", среднее опоздание " + ms.AvgLateness().Round(time.Minute).String() +
+			", серия " + strconv.Itoa(ms.CurrentStreak) + " дн." +
+			" (лучшая " + strconv.Itoa(ms.LongestStreak) + " дн.)")
+		if ms.OutOfScope > 0 {
+			report.WriteString(", вне задач команды " + ms.OutOfScope.String())
+		}
+		report.WriteString(".\n")
+	}
+
+	return report.String()
+}
+
+// CalcPeriodStats returns team members compliance for a period
+// from the first to the last day inclusive.
+// Non-working days are skipped.
+func (tsc TSCalc) CalcPeriodStats(
+	ctx context.Context,
+	from time.Time,
+	to time.Time,
+	team config.Team,
+) (TeamStats, error) {
+	ts := TeamStats{
+		From:    from,
+		To:      to,
+		Members: make([]MemberStats, len(team.Members)),
+	}
+	byAccID := make(map[string]*MemberStats, len(team.Members))
+	for i, member := range team.Members {
+		ts.Members[i].Member = member
+		byAccID[member.JiraAccID] = &ts.Members[i]
+	}
+
+	err := tsc.forEachWorkingDay(ctx, from, to, team, func(_ time.Time, trs TeamRemainSpends) {
+		for _, mrs := range trs {
+			if ms, ok := byAccID[mrs.Member.JiraAccID]; ok {
+				ms.addDay(mrs)
+			}
+		}
+	})
+	if err != nil {
+		return TeamStats{}, fmt.Errorf("calculating period stats: %w", err)
+	}
+
+	return ts, nil
+}
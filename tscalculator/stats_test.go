@@ -0,0 +1,119 @@
+package tscalculator
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/duke0x/ts-notifier/config"
+	mock_day_type_fetcher "github.com/duke0x/ts-notifier/mock/day_type_fetcher"
+	mock_worklog_fetcher "github.com/duke0x/ts-notifier/mock/work_log_fetcher"
+	"github.com/duke0x/ts-notifier/model"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTSCalc_CalcPeriodStats(t *testing.T) {
+	ctx := context.Background()
+
+	ctrl := gomock.NewController(t)
+	dc := mock_day_type_fetcher.NewMockDayTypeFetcher(ctrl)
+	wlf := mock_worklog_fetcher.NewMockWorkLogFetcher(ctrl)
+
+	member := config.Member{
+		Name:               "user1",
+		JiraAccID:          "user1_Jira_ID",
+		MattermostUsername: "user1_MM_ID",
+	}
+	team := config.Team{Name: "team1", Members: []config.Member{member}}
+	user := model.User(member.JiraAccID)
+
+	// friday to tuesday: 8h, weekend, weekend, 2h, 8h
+	days := []struct {
+		dayType model.DayType
+		spent   int
+	}{
+		{model.WorkDay, 8 * 3600},
+		{model.NoWorkDay, 0},
+		{model.NoWorkDay, 0},
+		{model.WorkDay, 2 * 3600},
+		{model.WorkDay, 8 * 3600},
+	}
+	from := time.Date(2023, 9, 1, 0, 0, 0, 0, time.UTC)
+	to := from.AddDate(0, 0, len(days)-1)
+
+	for i, d := range days {
+		day := from.AddDate(0, 0, i)
+		dc.EXPECT().FetchDayType(ctx, day).Return(d.dayType, nil)
+		if d.dayType == model.NoWorkDay {
+			continue
+		}
+
+		issues := []model.Issue{{ID: "1", Key: "PRJ-1"}}
+		wlf.EXPECT().UserWorkedIssuesByDate(ctx, user, day, "").Return(issues, nil)
+		wlf.EXPECT().WorkLogsPerIssues(
+			ctx, user, day, day.Add(time.Hour*23+time.Minute*59+time.Second*59), issues,
+		).Return([]model.WorkLog{{
+			Key:              "PRJ-1",
+			User:             user,
+			TimeSpentSeconds: d.spent,
+			Started:          day.Add(10 * time.Hour),
+			Created:          day.Add(11 * time.Hour),
+		}}, nil)
+	}
+
+	got, err := New(dc, wlf, nil).CalcPeriodStats(ctx, from, to, team)
+	require.NoError(t, err)
+	require.Equal(t, TeamStats{
+		From: from,
+		To:   to,
+		Members: []MemberStats{{
+			Member:        member,
+			WorkingDays:   3,
+			CompliantDays: 2,
+			MissingSpend:  6 * time.Hour,
+			LongestStreak: 1,
+			CurrentStreak: 1,
+			WorkLogs:      3,
+			TotalLateness: 3 * time.Hour,
+		}},
+	}, got)
+	require.Equal(t, time.Hour, got.Members[0].AvgLateness())
+}
+
+func TestTeamStats_Leaderboard(t *testing.T) {
+	ts := TeamStats{Members: []MemberStats{
+		{Member: config.Member{Name: "late"}, WorkingDays: 4, CompliantDays: 1, MissingSpend: time.Hour},
+		{Member: config.Member{Name: "idle"}, WorkingDays: 0},
+		{Member: config.Member{Name: "lazy"}, WorkingDays: 4, CompliantDays: 1, MissingSpend: 9 * time.Hour},
+		{Member: config.Member{Name: "good"}, WorkingDays: 4, CompliantDays: 3, MissingSpend: time.Hour},
+	}}
+
+	var got []string
+	for _, ms := range ts.Leaderboard() {
+		got = append(got, ms.Member.Name)
+	}
+	require.Equal(t, []string{"idle", "good", "late", "lazy"}, got)
+}
+
+func TestTeamStats_Report(t *testing.T) {
+	ts := TeamStats{
+		From: time.Date(2023, 9, 1, 0, 0, 0, 0, time.UTC),
+		To:   time.Date(2023, 9, 30, 0, 0, 0, 0, time.UTC),
+		Members: []MemberStats{{
+			Member:        config.Member{MattermostUsername: "ivanov.i"},
+			WorkingDays:   20,
+			CompliantDays: 19,
+			MissingSpend:  2 * time.Hour,
+			LongestStreak: 15,
+			CurrentStreak: 4,
+			WorkLogs:      10,
+			TotalLateness: 5 * time.Hour,
+		}},
+	}
+
+	want := "Статистика списания времени за 2023.09.01 - 2023.09.30:\n" +
+		"  1. @ivanov.i: списано в срок 19 из 20 дн. (95%), не списано 2h0m0s, " +
+		"среднее опоздание 30m0s, серия 4 дн. (лучшая 15 дн.).\n"
+	require.Equal(t, want, ts.Report())
+}
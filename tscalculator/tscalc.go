@@ -6,6 +6,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"log/slog"
 	"strings"
 	"time"
 
@@ -30,14 +31,21 @@ const (
 )
 
 type TSCalc struct {
-	dc  DayTypeFetcher
-	wlf WorkLogFetcher
+	dc     DayTypeFetcher
+	wlf    WorkLogFetcher
+	logger *slog.Logger
 }
 
-func New(dc DayTypeFetcher, wlf WorkLogFetcher) *TSCalc {
+// New returns time spends calculator, nil logger is replaced with slog.Default.
+func New(dc DayTypeFetcher, wlf WorkLogFetcher, logger *slog.Logger) *TSCalc {
+	if logger == nil {
+		logger = slog.Default()
+	}
+
 	return &TSCalc{
-		dc:  dc,
-		wlf: wlf,
+		dc:     dc,
+		wlf:    wlf,
+		logger: logger,
 	}
 }
 
@@ -52,6 +60,7 @@ type WorkLogFetcher interface {
 		ctx context.Context,
 		user model.User,
 		date time.Time,
+		scope string,
 	) ([]model.Issue, error)
 	WorkLogsPerIssues(
 		ctx context.Context,
@@ -66,6 +75,35 @@ type WorkLogFetcher interface {
 type MemberRemainSpend struct {
 	Member      config.Member
 	RemainSpend time.Duration
+	// WorkLogs are member work logs written for the day
+	WorkLogs []model.WorkLog
+	// Issues are the issues the member logged work to on the day
+	Issues []model.Issue
+	// OutOfScope are member work logs written for the day
+	// to issues out of the team scope, they are not counted
+	OutOfScope []model.WorkLog
+	// CommentViolations are work logs breaking the team comment rules
+	CommentViolations []CommentViolation
+}
+
+// LoggedSpend returns time logged to the team issues
+func (mrs MemberRemainSpend) LoggedSpend() time.Duration {
+	var total time.Duration
+	for _, wl := range mrs.WorkLogs {
+		total += time.Duration(wl.TimeSpentSeconds) * time.Second
+	}
+
+	return total
+}
+
+// OutOfScopeSpend returns time logged to issues out of the team scope
+func (mrs MemberRemainSpend) OutOfScopeSpend() time.Duration {
+	var total time.Duration
+	for _, wl := range mrs.OutOfScope {
+		total += time.Duration(wl.TimeSpentSeconds) * time.Second
+	}
+
+	return total
 }
 
 // TeamRemainSpends stores all team member time remain spends
@@ -81,7 +119,21 @@ func (trs TeamRemainSpends) RemainSpend() time.Duration {
 	return total
 }
 
-func (trs TeamRemainSpends) Report(day time.Time) string {
+// Reminded is a set of Jira account IDs of members reminded about the day
+// directly, reports name them instead of mentioning
+type Reminded map[string]bool
+
+// mention returns the member mention or name if the member was reminded
+func (r Reminded) mention(m config.Member) string {
+	if r[m.JiraAccID] {
+		return m.Name
+	}
+
+	return m.Mention()
+}
+
+// Report returns the team day report, reminded members are not mentioned
+func (trs TeamRemainSpends) Report(day time.Time, reminded Reminded) string {
 	// TODO: try to replace message building with template text/template
 	var report strings.Builder
 	report.WriteString("Отчет по списанию времени за " + day.Format("2006.01.02") + ":\n")
@@ -90,8 +142,12 @@ func (trs TeamRemainSpends) Report(day time.Time) string {
 	for _, urs := range trs {
 		if urs.RemainSpend > 0 {
 			emptyReport = false
-			report.WriteString("  - @" + urs.Member.MattermostUsername +
-				" нужно списать еще " + urs.RemainSpend.String() + ".\n")
+			report.WriteString("  - " + reminded.mention(urs.Member) +
+				" нужно списать еще " + urs.RemainSpend.String())
+			if reminded[urs.Member.JiraAccID] {
+				report.WriteString(" (напоминание отправлено лично)")
+			}
+			report.WriteString(".\n")
 		}
 	}
 
@@ -99,18 +155,57 @@ func (trs TeamRemainSpends) Report(day time.Time) string {
 		report.WriteString("Все молодцы, все списания произведены! :)")
 	}
 
+	outOfScope := true
+	for _, urs := range trs {
+		if len(urs.OutOfScope) == 0 {
+			continue
+		}
+		if outOfScope {
+			outOfScope = false
+			report.WriteString("\nСписано вне задач команды (не учтено):\n")
+		}
+		report.WriteString("  - " + reminded.mention(urs.Member) + ": " +
+			urs.OutOfScopeSpend().String() + " (" + issueKeys(urs.OutOfScope) + ").\n")
+	}
+
+	comments := true
+	for _, urs := range trs {
+		for _, cv := range urs.CommentViolations {
+			if comments {
+				comments = false
+				report.WriteString("\nЗамечания к комментариям списаний:\n")
+			}
+			report.WriteString("  - " + reminded.mention(urs.Member) + ": " + cv.String() + ".\n")
+		}
+	}
+
 	return report.String()
 }
 
+// issueKeys returns comma separated unique issue keys of the work logs
+func issueKeys(wls []model.WorkLog) string {
+	keys := make([]string, 0, len(wls))
+	seen := make(map[string]bool, len(wls))
+	for _, wl := range wls {
+		if !seen[wl.Key] {
+			seen[wl.Key] = true
+			keys = append(keys, wl.Key)
+		}
+	}
+
+	return strings.Join(keys, ", ")
+}
+
 // CalcDailyTimeSpends returns remaining time spends for a team per day.
 // It determines the model.DayType of the day and fetches all team members work logs.
 // Then it calculates remaining time spent depends on model.DayType.
 func (tsc TSCalc) CalcDailyTimeSpends(
+	ctx context.Context,
 	day time.Time,
 	team config.Team,
 ) (TeamRemainSpends, error) {
 	ds := day.Format(model.DayFormat)
-	dt, err := tsc.dc.FetchDayType(context.Background(), day)
+	dt, err := tsc.dc.FetchDayType(ctx, day)
 	if err != nil {
 		return nil, fmt.Errorf("checking day '%s': %w", ds, err)
 	}
@@ -122,32 +217,116 @@ func (tsc TSCalc) CalcDailyTimeSpends(
 	dayStart := day.Truncate(time.Hour * hoursPerDay).UTC()
 	dayEnd := dayStart.Add(time.Hour*23 + time.Minute*59 + time.Second*59)
 
-	ctx := context.Background()
+	rules, err := newCommentChecker(team.CommentRules)
+	if err != nil {
+		return nil, fmt.Errorf("team '%s' comment rules: %w", team.Name, err)
+	}
+
+	scope := team.Scope.Condition()
 	trs := TeamRemainSpends{}
 	for _, member := range team.Members {
 		user := model.User(member.JiraAccID)
-		issues, err := tsc.wlf.UserWorkedIssuesByDate(ctx, user, day)
+		// any fetching error fails the whole team calculation: partial data
+		// would show the member as one who has not logged anything
+		issues, err := tsc.wlf.UserWorkedIssuesByDate(ctx, user, day, scope)
 		if err != nil {
-			return nil, fmt.Errorf("fetching user worked issies: %w", err)
+			return nil, fmt.Errorf("fetching member '%s' worked issues: %w", member.Name, err)
 		}
 
 		wl, err := tsc.wlf.WorkLogsPerIssues(ctx, user, dayStart, dayEnd, issues)
 		if err != nil {
-			return nil, fmt.Errorf("fetching working issues: %w", err)
+			return nil, fmt.Errorf("fetching member '%s' work logs: %w", member.Name, err)
 		}
 
+		var outWL []model.WorkLog
+		if scope != "" {
+			outWL, err = tsc.outOfScopeWorkLogs(ctx, user, day, issues)
+			if err != nil {
+				return nil, fmt.Errorf("fetching member '%s' out of scope work logs: %w", member.Name, err)
+			}
+		}
+
+		wl = dayWorkLogs(user, wl, day)
 		tsWorked := calculateTimeSpent(user, wl, day)
 		tsRemain := remainTimeSpend(tsWorked, dt)
+		tsc.logger.DebugContext(ctx, "member time spends calculated",
+			"team", team.Name,
+			"member", member.Name,
+			"day", ds,
+			"day_type", dt,
+			"worked", tsWorked,
+			"remain", tsRemain,
+		)
 
 		trs = append(trs, MemberRemainSpend{
 			Member:      member,
 			RemainSpend: tsRemain,
+			WorkLogs:    wl,
+			Issues:      issues,
+			OutOfScope:  outWL,
+
+			CommentViolations: rules.check(wl),
 		})
 	}
 
 	return trs, nil
 }
 
+// outOfScopeWorkLogs returns user work logs of the day
+// written to issues other than in scope ones
+func (tsc TSCalc) outOfScopeWorkLogs(
+	ctx context.Context,
+	user model.User,
+	day time.Time,
+	inScope []model.Issue,
+) ([]model.WorkLog, error) {
+	all, err := tsc.wlf.UserWorkedIssuesByDate(ctx, user, day, "")
+	if err != nil {
+		return nil, err
+	}
+
+	counted := make(map[string]bool, len(inScope))
+	for _, issue := range inScope {
+		counted[issue.Key] = true
+	}
+	var out []model.Issue
+	for _, issue := range all {
+		if !counted[issue.Key] {
+			out = append(out, issue)
+		}
+	}
+	if len(out) == 0 {
+		return nil, nil
+	}
+
+	dayStart := day.Truncate(time.Hour * hoursPerDay).UTC()
+	dayEnd := dayStart.Add(time.Hour*23 + time.Minute*59 + time.Second*59)
+	wl, err := tsc.wlf.WorkLogsPerIssues(ctx, user, dayStart, dayEnd, out)
+	if err != nil {
+		return nil, err
+	}
+
+	return dayWorkLogs(user, wl, day), nil
+}
+
+// dayWorkLogs returns user work logs started in the day
+func dayWorkLogs(
+	user model.User,
+	wls []model.WorkLog,
+	day time.Time,
+) []model.WorkLog {
+	day = day.Truncate(time.Hour * hoursPerDay)
+
+	var dwl []model.WorkLog
+	for _, wl := range wls {
+		if wl.User == user && wl.Date().Equal(day) {
+			dwl = append(dwl, wl)
+		}
+	}
+
+	return dwl
+}
+
 // calculateTimeSpent returns total amount of all user work logs per day
 func calculateTimeSpent(
 	user model.User,
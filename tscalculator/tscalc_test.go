@@ -167,22 +167,24 @@ func TestTSCalc_CalcDailyTimeSpends(t *testing.T) {
 
 	dc.EXPECT().FetchDayType(ctx, day).Return(dayType, nil)
 
+	var wls []model.WorkLog
+	issues := []model.Issue{{ID: "asdni12312h31jg1h23", Key: "PRJ-1"}}
 	for _, member := range team.Members {
-		issues := []model.Issue{{ID: "asdni12312h31jg1h23", Key: "PRJ-1"}}
 		wlf.EXPECT().UserWorkedIssuesByDate(
 			ctx,
 			model.User(member.JiraAccID),
 			day,
+			"",
 		).Return(issues, nil)
 
 		dayStart := day.Truncate(time.Hour * hoursPerDay).UTC()
 		dayEnd := dayStart.Add(time.Hour*23 + time.Minute*59 + time.Second*59)
 
-		wls := []model.WorkLog{{
+		wls = []model.WorkLog{{
 			Key:              "PRJ-1",
 			User:             model.User(member.JiraAccID),
 			TimeSpentSeconds: 7200,
-			Started:          time.Now(),
+			Started:          day,
 			Comment:          "some work",
 		}}
 		wlf.EXPECT().WorkLogsPerIssues(
@@ -194,8 +196,8 @@ func TestTSCalc_CalcDailyTimeSpends(t *testing.T) {
 		).Return(wls, nil)
 	}
 
-	tsc := New(dc, wlf)
-	got, err := tsc.CalcDailyTimeSpends(day, team)
+	tsc := New(dc, wlf, nil)
+	got, err := tsc.CalcDailyTimeSpends(ctx, day, team)
 	if err != nil {
 		t.Errorf("CalcDailyTimeSpends() got error = %v, but error should be nil", err)
 		return
@@ -210,6 +212,8 @@ func TestTSCalc_CalcDailyTimeSpends(t *testing.T) {
 			Email:              "user1@example.com",
 		},
 		RemainSpend: 8*time.Hour - 2*time.Hour,
+		WorkLogs:    wls,
+		Issues:      issues,
 	}}
 	if !reflect.DeepEqual(got, want) {
 		t.Errorf("CalcDailyTimeSpends() got = %+v, want %+v", got, want)
@@ -246,7 +250,7 @@ func TestTSCalc_CalcDailyTimeSpendsErrServiceError(t *testing.T) {
 	}
 
 	wantErr := client.ErrServiceUnavailable
-	dayType, err := tsc.CalcDailyTimeSpends(day, team)
+	dayType, err := tsc.CalcDailyTimeSpends(ctx, day, team)
 	require.Equal(t, true, errors.Is(err, wantErr))
 	var trs TeamRemainSpends
 	require.Equal(t, trs, dayType)
@@ -335,12 +339,88 @@ func TestTeamRemainSpends_Report(t *testing.T) {
 			want: "Отчет по списанию времени за " + time.Now().UTC().Format("2006.01.02") + ":\n" +
 				"  - @ivanov.i нужно списать еще 1h0m0s.\n",
 		},
+		{
+			name: "out of scope spends",
+			trs: TeamRemainSpends{MemberRemainSpend{
+				Member: config.Member{
+					MattermostUsername: "ivanov.i",
+				},
+				OutOfScope: []model.WorkLog{
+					{Key: "OPS-1", TimeSpentSeconds: 3600},
+					{Key: "OPS-2", TimeSpentSeconds: 1800},
+					{Key: "OPS-1", TimeSpentSeconds: 1800},
+				},
+			}},
+			args: args{day: time.Now().UTC().Truncate(24 * time.Hour)},
+			want: "Отчет по списанию времени за " + time.Now().UTC().Format("2006.01.02") + ":\n" +
+				"Все молодцы, все списания произведены! :)\n" +
+				"Списано вне задач команды (не учтено):\n" +
+				"  - @ivanov.i: 2h0m0s (OPS-1, OPS-2).\n",
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			if got := tt.trs.Report(tt.args.day); got != tt.want {
+			if got := tt.trs.Report(tt.args.day, nil); got != tt.want {
 				t.Errorf("Report() = %v, want %v", got, tt.want)
 			}
 		})
 	}
 }
+
+func TestTSCalc_CalcDailyTimeSpendsOutOfScope(t *testing.T) {
+	ctx := context.Background()
+
+	ctrl := gomock.NewController(t)
+	dc := mock_day_type_fetcher.NewMockDayTypeFetcher(ctrl)
+	wlf := mock_worklog_fetcher.NewMockWorkLogFetcher(ctrl)
+
+	day := time.Now()
+	member := config.Member{Name: "user1", JiraAccID: "user1_Jira_ID", MattermostUsername: "user1_MM_ID"}
+	team := config.Team{
+		Name:    "team1",
+		Channel: "chan1",
+		Members: []config.Member{member},
+		Scope:   config.Scope{Projects: []string{"PRJ"}},
+	}
+	user := model.User(member.JiraAccID)
+	dayStart := day.Truncate(time.Hour * hoursPerDay).UTC()
+	dayEnd := dayStart.Add(time.Hour*23 + time.Minute*59 + time.Second*59)
+
+	dc.EXPECT().FetchDayType(ctx, day).Return(model.WorkDay, nil)
+
+	inScope := []model.Issue{{ID: "1", Key: "PRJ-1"}}
+	outScope := []model.Issue{{ID: "2", Key: "OPS-1"}}
+	wlf.EXPECT().UserWorkedIssuesByDate(ctx, user, day, `project in ("PRJ")`).Return(inScope, nil)
+	wlf.EXPECT().UserWorkedIssuesByDate(ctx, user, day, "").Return(append(inScope, outScope...), nil)
+
+	inWL := []model.WorkLog{{Key: "PRJ-1", User: user, TimeSpentSeconds: 6 * 3600, Started: day}}
+	outWL := []model.WorkLog{{Key: "OPS-1", User: user, TimeSpentSeconds: 2 * 3600, Started: day}}
+	wlf.EXPECT().WorkLogsPerIssues(ctx, user, dayStart, dayEnd, inScope).Return(inWL, nil)
+	wlf.EXPECT().WorkLogsPerIssues(ctx, user, dayStart, dayEnd, outScope).Return(outWL, nil)
+
+	got, err := New(dc, wlf, nil).CalcDailyTimeSpends(ctx, day, team)
+	require.NoError(t, err)
+	require.Equal(t, TeamRemainSpends{{
+		Member:      member,
+		RemainSpend: 2 * time.Hour,
+		WorkLogs:    inWL,
+		Issues:      inScope,
+		OutOfScope:  outWL,
+	}}, got)
+	require.Equal(t, 2*time.Hour, got[0].OutOfScopeSpend())
+}
+
+func TestTeamRemainSpends_ReportReminded(t *testing.T) {
+	day := time.Date(2023, 9, 1, 0, 0, 0, 0, time.UTC)
+	trs := TeamRemainSpends{
+		{Member: config.Member{Name: "Ivan Ivanov", JiraAccID: "acc1", MattermostUsername: "ivanov.i"}, RemainSpend: time.Hour},
+		{Member: config.Member{Name: "Petr Petrov", JiraAccID: "acc2", MattermostUsername: "petrov.p"}, RemainSpend: 2 * time.Hour},
+	}
+
+	// remaining time of the reminded member is kept, the member is named only
+	require.Equal(t, "Отчет по списанию времени за 2023.09.01:\n"+
+		"  - Ivan Ivanov нужно списать еще 1h0m0s (напоминание отправлено лично).\n"+
+		"  - @petrov.p нужно списать еще 2h0m0s.\n", trs.Report(day, Reminded{"acc1": true}))
+	require.Equal(t, "Отчет по списанию времени за 2023.09.01, нужно списать: Ivan Ivanov, @petrov.p",
+		trs.Message(day, Reminded{"acc1": true}).Title)
+}